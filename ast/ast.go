@@ -142,6 +142,8 @@ const (
 	AST_RETURN
 	AST_CONTINUE
 	AST_BREAK
+	AST_NEW
+	AST_DELETE
 )
 
 type NodeBuilder struct {
@@ -432,7 +434,7 @@ func (ts *Scanner) ParseStatement() Statement {
 	t := ts.At(0)
 	if t.Type == p.T_IDENT {
 		switch t.Value {
-		case "var":
+		case "var", "static", "globalvar":
 			if stmt, err := ts.ParseVarDecl(); err == nil {
 				ts.EatSemicolon()
 				return &stmt
@@ -502,15 +504,8 @@ func (ts *Scanner) ParseStatement() Statement {
 			}
 			return nil
 
-		case "new":
-			if stmt, err := ts.ParseKwdStmt("new", AST_RETURN, VT_OPTIONAL); err == nil {
-				ts.EatSemicolon()
-				return &stmt
-			}
-			return nil
-
 		case "delete":
-			if stmt, err := ts.ParseKwdStmt("delete", AST_RETURN, VT_OPTIONAL); err == nil {
+			if stmt, err := ts.ParseKwdStmt("delete", AST_DELETE, VT_OPTIONAL); err == nil {
 				ts.EatSemicolon()
 				return &stmt
 			}
@@ -619,9 +614,14 @@ func (v *VarDecl) End() p.Location {
 	return v.Name.Loc
 }
 
+// Keyword returns how the variable was declared: "var", "static" or
+// "globalvar".
+func (v *VarDecl) Keyword() string { return v.keyword.Value }
+
 func (v *VarDecl) Render(nb *NodeBuilder) {
 	nb.RenderNode(
 		&v.Base,
+		NodeField{"Keyword", v.keyword.Value},
 		NodeField{"Name", v.Name.Value},
 		NodeField{"Value", v.Value},
 	)
@@ -635,6 +635,9 @@ func (ts *Scanner) ParseVarDecl() (VarDecl, error) {
 	if kwd == nil {
 		kwd = ts.ParseExact(0, "static")
 	}
+	if kwd == nil {
+		kwd = ts.ParseExact(0, "globalvar")
+	}
 	name := ts.ParseType(1, p.T_IDENT)
 
 	if kwd == nil || name == nil {
@@ -758,7 +761,7 @@ func (ts *Scanner) ParseFuncDecl(anonymous bool) (FuncDecl, error) {
 		ts.Move(1)
 
 	} else {
-		name := ts.ParseType(1, p.T_IDENT)
+		name = ts.ParseType(1, p.T_IDENT)
 		if kwd == nil || name == nil {
 			return FuncDecl{}, errors.New("Not a function")
 		}
@@ -992,11 +995,11 @@ func (ts *Scanner) ParseExprPart() (Node, error) {
 		}
 
 		if ts.ParseExact(0, "new") != nil {
-			if stmt, err := ts.ParseKwdStmt("new", AST_RETURN, VT_OPTIONAL); err == nil {
-				ts.EatSemicolon()
+			stmt, err := ts.ParseNew()
+			if err == nil {
 				return &stmt, nil
 			}
-			return nil, errors.New("Expected an expression")
+			return nil, err
 		}
 
 		ident, err := ts.ParseIdent()
@@ -1214,7 +1217,7 @@ func (ts *Scanner) ParseStruct() (Struct, error) {
 	ts.Commit()
 
 	return Struct{
-		Base:       Base{AST_LITERAL_STRING},
+		Base:       Base{AST_LITERAL_STRUCT},
 		openCurly:  opening,
 		closeCurly: closing,
 		Fields:     fields,
@@ -1300,6 +1303,19 @@ func (ts *Scanner) ParseCall(fn Node) (Node, error) {
 	g := ts.GuardStart()
 	defer ts.GuardEnd(g)
 
+	call, err := ts.parseCallArgs(fn)
+	if err != nil {
+		return nil, err
+	}
+	return ts.ParseExpr(call)
+}
+
+// parseCallArgs parses the parenthesised arguments of a call to fn, and
+// nothing after them.
+func (ts *Scanner) parseCallArgs(fn Node) (*Call, error) {
+	g := ts.GuardStart()
+	defer ts.GuardEnd(g)
+
 	opening := ts.ParseType(0, p.T_LPAREN)
 	if opening == nil {
 		return nil, errors.New("Missing opening parenthesis")
@@ -1332,13 +1348,13 @@ func (ts *Scanner) ParseCall(fn Node) (Node, error) {
 	ts.Move(1)
 	ts.Commit()
 
-	return ts.ParseExpr(&Call{
+	return &Call{
 		Base:       Base{AST_CALL},
 		openParen:  opening,
 		closeParen: closing,
 		Function:   fn,
 		Params:     params,
-	})
+	}, nil
 }
 
 type Attr struct {
@@ -1758,6 +1774,45 @@ func (ts *Scanner) ParseKwdStmt(
 	}, nil
 }
 
+// ParseNew parses `new Constructor(args)`. Its Value is the call, so
+// that anything following it, like a method call, applies to the new
+// struct rather than to the constructor.
+func (ts *Scanner) ParseNew() (KwdStmt, error) {
+	g := ts.GuardStart()
+	defer ts.GuardEnd(g)
+
+	kwd := ts.ParseExact(0, "new")
+	if kwd == nil {
+		return KwdStmt{}, errors.New("Missing new keyword")
+	}
+
+	ts.Save()
+	ts.Move(1)
+
+	ident, err := ts.ParseIdent()
+	if err != nil {
+		ts.Restore()
+		return KwdStmt{}, errors.New("Expected a constructor")
+	}
+
+	var value Node = &ident
+	if ts.ParseType(0, p.T_LPAREN) != nil {
+		call, err := ts.parseCallArgs(value)
+		if err != nil {
+			ts.Restore()
+			return KwdStmt{}, err
+		}
+		value = call
+	}
+
+	ts.Commit()
+	return KwdStmt{
+		Base:  Base{AST_NEW},
+		Kwd:   kwd,
+		Value: value,
+	}, nil
+}
+
 // BlockStmt represents a statement of the general form
 //
 //	kwd (Condition) {Body}
@@ -1987,7 +2042,7 @@ func (ts *Scanner) ParseForLoop() (ForLoop, error) {
 	var body Node
 	b, err := ts.ParseBlock()
 	if err != nil {
-		body := ts.ParseStatement()
+		body = ts.ParseStatement()
 		if body == nil {
 			ts.Restore()
 			return ForLoop{}, errors.New("Missing for loop body")
@@ -0,0 +1,121 @@
+package ast
+
+import (
+	p "gmtc/parser"
+	"testing"
+)
+
+func parse(t *testing.T, src string) []Statement {
+	t.Helper()
+	ts, err := p.TokenizeString(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := ParseAST(ts)
+	if err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	return tree.Children
+}
+
+// parseOne parses a single statement of type T.
+func parseOne[T Node](t *testing.T, src string) T {
+	t.Helper()
+	stmts := parse(t, src)
+	if len(stmts) != 1 {
+		t.Fatalf("%q parsed as %v statements", src, len(stmts))
+	}
+	stmt, ok := stmts[0].(T)
+	if !ok {
+		t.Fatalf("%q parsed as %T", src, stmts[0])
+	}
+	return stmt
+}
+
+func TestStructLiteral(t *testing.T) {
+	decl := parseOne[*VarDecl](t, `var s = { a: 1, b: "two" };`)
+	st, ok := decl.Value.(*Struct)
+	if !ok || st.ASTType() != AST_LITERAL_STRUCT || len(st.Fields) != 2 {
+		t.Errorf("got %v", NodeString(decl.Value))
+	}
+}
+
+func TestNamedFunction(t *testing.T) {
+	fn := parseOne[*FuncDecl](t, "function scr_move(_a) { return _a; }")
+	if fn.Name == nil || fn.Name.Value != "scr_move" {
+		t.Errorf("function has name %v", fn.Name)
+	}
+	if len(fn.Args) != 1 || len(fn.Body.Statements) != 1 {
+		t.Errorf("got %v", NodeString(fn))
+	}
+}
+
+func TestForLoopBody(t *testing.T) {
+	tests := []string{
+		"for (var i = 0; i < 3; i++) x += i;",
+		"for (var i = 0; i < 3; i++) { x += i; }",
+	}
+	for _, src := range tests {
+		loop := parseOne[*ForLoop](t, src)
+		if loop.Body == nil {
+			t.Errorf("%q has no body", src)
+		}
+	}
+}
+
+func TestVarKeywords(t *testing.T) {
+	tests := []struct {
+		src     string
+		keyword string
+		name    string
+	}{
+		{"var a = 1;", "var", "a"},
+		{"static count = 0;", "static", "count"},
+		{"globalvar score;", "globalvar", "score"},
+	}
+	for _, test := range tests {
+		decl := parseOne[*VarDecl](t, test.src)
+		if decl.Keyword() != test.keyword || decl.Name.Value != test.name {
+			t.Errorf("%q declares %v %v", test.src, decl.Keyword(), decl.Name.Value)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	decl := parseOne[*VarDecl](t, "var v = new Vec(1, 2);")
+	n, ok := decl.Value.(*KwdStmt)
+	if !ok || n.ASTType() != AST_NEW {
+		t.Fatalf("got %v", NodeString(decl.Value))
+	}
+	call, ok := n.Value.(*Call)
+	if !ok || len(call.Params) != 2 {
+		t.Fatalf("new has value %v", NodeString(n.Value))
+	}
+	if ident, ok := call.Function.(*Simple); !ok || ident.Value.Value != "Vec" {
+		t.Errorf("new has value %v", NodeString(n.Value))
+	}
+
+	// What follows the call applies to the new struct.
+	decl = parseOne[*VarDecl](t, "var l = new Vec(1, 2).len();")
+	call, ok = decl.Value.(*Call)
+	if !ok {
+		t.Fatalf("got %v", NodeString(decl.Value))
+	}
+	attr, ok := call.Function.(*Attr)
+	if !ok || attr.Name.Value != "len" || attr.Value.ASTType() != AST_NEW {
+		t.Errorf("got %v", NodeString(decl.Value))
+	}
+
+	// new isn't a return statement.
+	fn := parseOne[*FuncDecl](t, "function f() { new Vec(); }")
+	if len(fn.Body.Statements) != 1 || fn.Body.Statements[0].ASTType() == AST_RETURN {
+		t.Errorf("got %v", NodeString(fn))
+	}
+}
+
+func TestDelete(t *testing.T) {
+	stmt := parseOne[*KwdStmt](t, "delete s;")
+	if stmt.ASTType() != AST_DELETE || stmt.Value == nil {
+		t.Errorf("got %v", NodeString(stmt))
+	}
+}
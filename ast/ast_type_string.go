@@ -44,11 +44,13 @@ func _() {
 	_ = x[AST_RETURN-33]
 	_ = x[AST_CONTINUE-34]
 	_ = x[AST_BREAK-35]
+	_ = x[AST_NEW-36]
+	_ = x[AST_DELETE-37]
 }
 
-const _AST_TYPE_name = "UNKNOWNSCRIPTIDENTLITERAL_STRINGLITERAL_NUMBERLITERAL_BOOLLITERAL_ARRAYLITERAL_STRUCTENUMSTRUCT_FIELDBINOPUNOP_PREFIXUNOP_POSTFIXCALLACCESSATTRTERNARYBLOCKFUNC_DECLARGVAR_DECLASSIGNIFELIFFORWHILEDOUNTILREPEATWITHCASEDEFAULTSWITCHTRY_CATCHRETURNCONTINUEBREAK"
+const _AST_TYPE_name = "UNKNOWNSCRIPTIDENTLITERAL_STRINGLITERAL_NUMBERLITERAL_BOOLLITERAL_ARRAYLITERAL_STRUCTENUMSTRUCT_FIELDBINOPUNOP_PREFIXUNOP_POSTFIXCALLACCESSATTRTERNARYBLOCKFUNC_DECLARGVAR_DECLASSIGNIFELIFFORWHILEDOUNTILREPEATWITHCASEDEFAULTSWITCHTRY_CATCHRETURNCONTINUEBREAKNEWDELETE"
 
-var _AST_TYPE_index = [...]uint16{0, 7, 13, 18, 32, 46, 58, 71, 85, 89, 101, 106, 117, 129, 133, 139, 143, 150, 155, 164, 167, 175, 181, 183, 187, 190, 195, 202, 208, 212, 216, 223, 229, 238, 244, 252, 257}
+var _AST_TYPE_index = [...]uint16{0, 7, 13, 18, 32, 46, 58, 71, 85, 89, 101, 106, 117, 129, 133, 139, 143, 150, 155, 164, 167, 175, 181, 183, 187, 190, 195, 202, 208, 212, 216, 223, 229, 238, 244, 252, 257, 260, 266}
 
 func (i AST_TYPE) String() string {
 	if i < 0 || i >= AST_TYPE(len(_AST_TYPE_index)-1) {
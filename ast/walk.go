@@ -0,0 +1,105 @@
+package ast
+
+// Children returns the nodes directly below n, in source order.
+func Children(n Node) []Node {
+	out := make([]Node, 0)
+	add := func(ns ...Node) {
+		for _, c := range ns {
+			if c != nil {
+				out = append(out, c)
+			}
+		}
+	}
+	addStmts := func(stmts []Statement) {
+		for _, stmt := range stmts {
+			add(stmt)
+		}
+	}
+
+	switch n := n.(type) {
+	case *ScriptNode:
+		addStmts(n.Children)
+	case *Block:
+		addStmts(n.Statements)
+	case *VarDecl:
+		add(n.Value)
+	case *Assign:
+		add(n.Left, n.Right)
+	case *Binop:
+		add(n.Left, n.Right)
+	case *Unop:
+		add(n.Value)
+	case *Ternary:
+		add(n.Cond, n.OnTrue, n.OnFalse)
+	case *Call:
+		add(n.Function)
+		add(n.Params...)
+	case *Attr:
+		add(n.Value)
+	case *Access:
+		add(n.Value, n.Access, n.SecondAccess)
+	case *Array:
+		add(n.Items...)
+	case *Struct:
+		for i := range n.Fields {
+			add(&n.Fields[i])
+		}
+	case *Field:
+		add(n.Value)
+	case *FuncDecl:
+		for i := range n.Args {
+			add(&n.Args[i])
+		}
+		if n.Parent != nil {
+			add(n.Parent)
+		}
+		add(&n.Body)
+	case *Arg:
+		add(n.Default)
+	case *KwdStmt:
+		add(n.Value)
+	case *BlockStmt:
+		add(n.Condition, n.Body)
+	case *IfStmt:
+		add(n.Condition, n.Body)
+		for i := range n.Elseifs {
+			add(&n.Elseifs[i])
+		}
+		add(n.Else)
+	case *ForLoop:
+		add(&n.Assign, n.Cond, n.Oper, n.Body)
+	case *Switch:
+		add(n.Value)
+		for i := range n.Cases {
+			add(&n.Cases[i])
+		}
+	case *Case:
+		add(n.Value)
+		addStmts(n.Code)
+	case *Enum:
+		for _, m := range n.Members {
+			add(m.Value)
+		}
+	case *TryCatch:
+		add(&n.TryBlock)
+		if n.CatchBlock != nil {
+			add(&n.CatchBlock.Block)
+		}
+		if n.FinallyBlock != nil {
+			add(n.FinallyBlock)
+		}
+	}
+
+	return out
+}
+
+// Inspect calls fn on n and, for as long as fn returns true, on each of
+// the nodes below it, depth first.
+func Inspect(n Node, fn func(Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, c := range Children(n) {
+		Inspect(c, fn)
+	}
+}
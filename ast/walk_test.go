@@ -0,0 +1,34 @@
+package ast
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	stmts := parse(t, `
+var a = f(b, c + d[e]);
+if (g) { h.i = { j: k }; } else l();
+function m(_n = o) { return p; }
+`)
+	// Identifiers are visited in source order, and returning false skips
+	// what is below a node.
+	got := make([]string, 0)
+	for _, stmt := range stmts {
+		Inspect(stmt, func(n Node) bool {
+			if ident, ok := n.(*Simple); ok && ident.ASTType() == AST_IDENT {
+				got = append(got, ident.Value.Value)
+			}
+			fn, ok := n.(*FuncDecl)
+			return !ok || fn.Name.Value != "m"
+		})
+	}
+	want := []string{"f", "b", "c", "d", "e", "g", "h", "k", "l"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if len(Children(stmts[2])) != 2 {
+		t.Errorf("m has children %v", Children(stmts[2]))
+	}
+}
@@ -1,17 +1,88 @@
 package main
 
 import (
+	"bytes"
 	"flag"
+	"fmt"
 	"io"
 	"os"
+	"path"
+	fp "path/filepath"
+	"runtime"
 	"strings"
+	"time"
 	"gmtc/project"
+	"gmtc/typecheck"
+	"gmtc/utils"
 )
 
 var filepath = flag.String("path", "", "Path to the file to be parsed")
 var stdin = flag.Bool("stdin", false, "Read from stdin")
+var buffer = flag.String("buffer", "", "With -path and -stdin, check stdin as the contents of this file in the project")
+var jobs = flag.Int("j", runtime.NumCPU(), "Number of files to parse in parallel")
+var cacheDir = flag.String("cache", defaultCacheDir(), "Directory for cached parse results")
+var noCache = flag.Bool("no-cache", false, "Don't read or write the parse cache")
+var clearCache = flag.Bool("clear-cache", false, "Empty the parse cache before running")
+var interval = flag.Duration("interval", 500*time.Millisecond, "How often watch mode polls for changes")
+
+// commands maps subcommand names, given as the first argument after the
+// flags, to their implementations.
+var commands = map[string]func(args []string) error{
+	"watch":      WatchCmd,
+	"doctor":     DoctorCmd,
+	"new-script": NewScriptCmd,
+	"new-object": NewObjectCmd,
+	"add-event":  AddEventCmd,
+	"normalise":  NormaliseCmd,
+	"merge-yyp":  MergeYYPCmd,
+	"summarise":  SummariseCmd,
+
+	"update-builtins": UpdateBuiltinsCmd,
+	"bindings":        BindingsCmd,
+	"check":           CheckCmd,
+	"types":           TypesCmd,
+
+	"list-package":   ListPackageCmd,
+	"import-package": ImportPackageCmd,
+	"export-package": ExportPackageCmd,
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return path.Join(dir, "gmtc")
+}
+
+func OpenCache() (*project.Cache, error) {
+	if *noCache || len(*cacheDir) == 0 {
+		return nil, nil
+	}
+
+	cache, err := project.OpenCache(*cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if *clearCache {
+		err = cache.Clear()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cache, nil
+}
 
 func ParseProject(p project.Project) error {
+	cache, err := OpenCache()
+	if err != nil {
+		return err
+	}
+
+	p.Jobs = *jobs
+	p.Cache = cache
 	p.Parse()
 	return p.AllErrors().Merge()
 }
@@ -22,17 +93,70 @@ func LoadProject(filepath string) (project.Project, error) {
 	return p, nil
 }
 
-func ParseFile(filepath string) error {
+func LoadFile(filepath string) (project.Project, error) {
 	if strings.HasSuffix(filepath, ".yyp") {
-		p, err := LoadProject(filepath)
-		if err != nil { return err }
-		return ParseProject(p)
+		return LoadProject(filepath)
 	}
+	return project.SingleFile(filepath), nil
+}
 
-	p := project.SingleFile(filepath)
+func ParseFile(filepath string) error {
+	p, err := LoadFile(filepath)
+	if err != nil { return err }
 	return ParseProject(p)
 }
 
+func PrintSummary(p *project.Project) {
+	errs := Diagnostics(p)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	fmt.Printf("%v problem(s) in %v\n", len(errs), p.File)
+}
+
+// Diagnostics returns everything check reports about a parsed project:
+// parse errors, reads of names that aren't declared anywhere, code that
+// doesn't match its doc comments, reads of fields structs don't have,
+// calls with the wrong number of arguments and instance variables
+// objects' events may read before they are set.
+func Diagnostics(p *project.Project) utils.Errors {
+	b := typecheck.Resolve(p, typecheck.DefaultBuiltins())
+	types := typecheck.Infer(p, b)
+	errs := p.AllErrors()
+	errs = errs.Extend(typecheck.CheckUndefined(p, b))
+	errs = errs.Extend(typecheck.CheckAnnotations(p, types))
+	errs = errs.Extend(typecheck.CheckFields(p, types))
+	errs = errs.Extend(typecheck.CheckArgCounts(p, types))
+	errs = errs.Extend(typecheck.CheckInstanceVars(p, b))
+	return errs
+}
+
+func WatchCmd(args []string) error {
+	if len(*filepath) == 0 {
+		return fmt.Errorf("watch needs a -path")
+	}
+
+	p, err := LoadFile(*filepath)
+	if err != nil { return err }
+
+	cache, err := OpenCache()
+	if err != nil { return err }
+
+	p.Jobs = *jobs
+	p.Cache = cache
+	p.Parse()
+	PrintSummary(&p)
+
+	w := project.NewWatcher(&p)
+	return w.Watch(*interval, func(changed []string) {
+		fmt.Printf("\n[%v]\n", time.Now().Format(time.TimeOnly))
+		for _, file := range changed {
+			fmt.Println("changed:", file)
+		}
+		PrintSummary(&p)
+	})
+}
+
 func ReadStdin() (string, error) {
 	text, err := io.ReadAll(os.Stdin)
 	if err != nil {
@@ -51,11 +175,476 @@ func ParseStdin() error {
 	return ParseProject(p)
 }
 
+func DoctorCmd(args []string) error {
+	if !strings.HasSuffix(*filepath, ".yyp") {
+		return fmt.Errorf("doctor needs a -path to a .yyp file")
+	}
+
+	p, err := LoadProject(*filepath)
+	if err != nil { return err }
+
+	errs := p.Validate()
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	fmt.Printf("%v problem(s) in %v\n", len(errs), p.File)
+
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// folderPath turns a -folder argument, either a folder's name like
+// "Scripts" or its path like "folders/Scripts.yy", into its path.
+func folderPath(folder string) string {
+	if len(folder) == 0 || strings.HasSuffix(folder, ".yy") {
+		return folder
+	}
+	return "folders/" + folder + ".yy"
+}
+
+func yypProject(cmd string) (project.Project, error) {
+	if !strings.HasSuffix(*filepath, ".yyp") {
+		return project.Project{}, fmt.Errorf("%v needs a -path to a .yyp file", cmd)
+	}
+	return LoadProject(*filepath)
+}
+
+func NewScriptCmd(args []string) error {
+	flags := flag.NewFlagSet("new-script", flag.ExitOnError)
+	name := flags.String("name", "", "Name of the new script")
+	folder := flags.String("folder", "Scripts", "Folder to put the script in")
+	flags.Parse(args)
+
+	p, err := yypProject("new-script")
+	if err != nil { return err }
+	return p.NewScript(*name, folderPath(*folder))
+}
+
+func NewObjectCmd(args []string) error {
+	flags := flag.NewFlagSet("new-object", flag.ExitOnError)
+	name := flags.String("name", "", "Name of the new object")
+	folder := flags.String("folder", "Objects", "Folder to put the object in")
+	flags.Parse(args)
+
+	p, err := yypProject("new-object")
+	if err != nil { return err }
+	return p.NewObject(*name, folderPath(*folder))
+}
+
+func AddEventCmd(args []string) error {
+	flags := flag.NewFlagSet("add-event", flag.ExitOnError)
+	object := flags.String("object", "", "Object to add the event to")
+	event := flags.String("event", "", "Event type, e.g. Create, Step or Collision")
+	num := flags.Int("num", 0, "Event number, e.g. the alarm or key")
+	with := flags.String("with", "", "For collision events, the other object")
+	flags.Parse(args)
+
+	evtype, err := project.ParseEventType(*event)
+	if err != nil { return err }
+
+	p, err := yypProject("add-event")
+	if err != nil { return err }
+	return p.AddEvent(*object, evtype, *num, *with)
+}
+
+func SummariseCmd(args []string) error {
+	p, err := yypProject("summarise")
+	if err != nil { return err }
+
+	cache, err := OpenCache()
+	if err != nil { return err }
+
+	// Everything is parsed from scratch, rather than from the summary
+	// being replaced.
+	p.Summary = nil
+	p.Jobs = *jobs
+	p.Cache = cache
+	p.Parse()
+
+	b := typecheck.Resolve(&p, typecheck.DefaultBuiltins())
+	sum := p.Summarise()
+	typecheck.SummaryTypes(sum, b, typecheck.Infer(&p, b))
+	err = p.WriteSummary(sum)
+	if err != nil { return err }
+
+	fmt.Printf("summarised %v library script(s) to %v\n", len(sum.Scripts), p.SummaryPath())
+	return nil
+}
+
+// UpdateBuiltinsCmd brings a builtins database up to date with an
+// fnames listing from a GameMaker runtime.
+func UpdateBuiltinsCmd(args []string) error {
+	flags := flag.NewFlagSet("update-builtins", flag.ExitOnError)
+	fnames := flags.String("fnames", "", "Path to the runtime's fnames file")
+	from := flags.String("from", "", "Database to update, instead of the built-in one")
+	out := flags.String("out", "", "Where to write the result, instead of stdout")
+	flags.Parse(args)
+
+	if *fnames == "" {
+		return fmt.Errorf("update-builtins needs -fnames")
+	}
+
+	bs := typecheck.DefaultBuiltins()
+	if *from != "" {
+		f, err := os.Open(*from)
+		if err != nil { return err }
+		bs, err = typecheck.ParseBuiltins(f)
+		f.Close()
+		if err != nil { return fmt.Errorf("%v: %w", *from, err) }
+	}
+
+	f, err := os.Open(*fnames)
+	if err != nil { return err }
+	defer f.Close()
+
+	bs, err = typecheck.ImportFnames(bs, f)
+	if err != nil { return fmt.Errorf("%v: %w", *fnames, err) }
+
+	if *out == "" {
+		return bs.Write(os.Stdout)
+	}
+	w, err := os.Create(*out)
+	if err != nil { return err }
+	defer w.Close()
+	return bs.Write(w)
+}
+
+// parsedProject loads and parses the project or script at -path. The
+// cache is bypassed, as the typecheck passes need every script's AST.
+func parsedProject() (*project.Project, error) {
+	if len(*filepath) == 0 {
+		return nil, fmt.Errorf("needs a -path")
+	}
+	p, err := LoadFile(*filepath)
+	if err != nil { return nil, err }
+
+	p.Jobs = *jobs
+	p.Parse()
+	return &p, nil
+}
+
+// resolvedProject also resolves the identifiers of the parsed project.
+func resolvedProject() (*project.Project, *typecheck.Bindings, error) {
+	p, err := parsedProject()
+	if err != nil { return nil, nil, err }
+	return p, typecheck.Resolve(p, typecheck.DefaultBuiltins()), nil
+}
+
+// scriptArg returns the script named by a -file argument, which may be
+// left out when -path is a single script.
+func scriptArg(p *project.Project, file string) (*project.ResGMScript, error) {
+	if file == "" && p.Kind == project.PK_SCRIPT {
+		return p.Scripts()[0], nil
+	}
+	if file == "" {
+		return nil, fmt.Errorf("needs a -file")
+	}
+	return p.FindScript(file)
+}
+
+func printBinding(bd typecheck.Binding) {
+	loc := bd.Ident.Loc
+	fmt.Printf("%v:%v\t%v\t%v", loc.Line+1, loc.Char+1, bd.Ident.Value, bd.Symbol.Kind)
+	if bd.Symbol.Owner != "" {
+		fmt.Printf("\t%v", bd.Symbol.Owner)
+	}
+	fmt.Println()
+}
+
+// BindingsCmd lists what the identifiers of a script refer to.
+func BindingsCmd(args []string) error {
+	flags := flag.NewFlagSet("bindings", flag.ExitOnError)
+	file := flags.String("file", "", "Script to list, as a path to its .gml")
+	line := flags.Int("line", 0, "Only show the identifier on this line")
+	col := flags.Int("col", 0, "Column of the identifier to show, with -line")
+	flags.Parse(args)
+
+	p, b, err := resolvedProject()
+	if err != nil { return err }
+	scr, err := scriptArg(p, *file)
+	if err != nil { return err }
+
+	if *line > 0 {
+		bd := b.At(scr, *line-1, *col-1)
+		if bd == nil {
+			return fmt.Errorf("no identifier at %v:%v:%v", scr.GMLPath, *line, *col)
+		}
+		printBinding(*bd)
+		return nil
+	}
+
+	for _, bd := range b.Scripts[scr] {
+		printBinding(bd)
+	}
+	return nil
+}
+
+// TypesCmd lists the inferred types of what a script declares.
+func TypesCmd(args []string) error {
+	flags := flag.NewFlagSet("types", flag.ExitOnError)
+	file := flags.String("file", "", "Script to list, as a path to its .gml")
+	flags.Parse(args)
+
+	p, b, err := resolvedProject()
+	if err != nil { return err }
+	scr, err := scriptArg(p, *file)
+	if err != nil { return err }
+
+	types := typecheck.Infer(p, b)
+	for _, decl := range types.Declarations(scr) {
+		loc := decl.Ident.Loc
+		fmt.Printf("%v:%v\t%v\t%v\n", loc.Line+1, loc.Char+1, decl.Ident.Value, decl.Type)
+	}
+	return nil
+}
+
+// CheckCmd reports the project's Diagnostics.
+func CheckCmd(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.Parse(args)
+
+	p, err := parsedProject()
+	if err != nil { return err }
+
+	errs := Diagnostics(p)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	fmt.Printf("%v problem(s)\n", len(errs))
+
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func NormaliseCmd(args []string) error {
+	flags := flag.NewFlagSet("normalise", flag.ExitOnError)
+	check := flags.Bool("check", false, "Only report whether the file is normalised")
+	flags.Parse(args)
+
+	if !strings.HasSuffix(*filepath, ".yyp") {
+		return fmt.Errorf("normalise needs a -path to a .yyp file")
+	}
+
+	fsys := project.OSFS{}
+	doc, err := project.ReadYY(fsys, *filepath)
+	if err != nil { return err }
+
+	before := doc.Bytes()
+	project.NormaliseYYP(doc)
+
+	if *check {
+		if !bytes.Equal(before, doc.Bytes()) {
+			fmt.Printf("%v is not normalised\n", *filepath)
+			os.Exit(1)
+		}
+		return nil
+	}
+	return project.WriteYY(fsys, *filepath, doc)
+}
+
+// MergeYYPCmd merges the .yyp at ours with the one at theirs, both
+// changed from the one at base, writing the result over ours. This fits
+// git's merge driver interface, e.g. `driver = gmtc merge-yyp %O %A %B`.
+// An empty base file stands for there being no common version.
+func MergeYYPCmd(args []string) error {
+	flags := flag.NewFlagSet("merge-yyp", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: gmtc merge-yyp BASE OURS THEIRS")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 3 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	fsys := project.OSFS{}
+	var base *project.YYDocument
+	base_src, err := fsys.ReadFile(flags.Arg(0))
+	if err != nil { return err }
+	if len(bytes.TrimSpace(base_src)) > 0 {
+		base, err = project.ParseYY(base_src)
+		if err != nil { return fmt.Errorf("%v: %w", flags.Arg(0), err) }
+	}
+	ours, err := project.ReadYY(fsys, flags.Arg(1))
+	if err != nil { return err }
+	theirs, err := project.ReadYY(fsys, flags.Arg(2))
+	if err != nil { return err }
+
+	merged, errs := project.MergeYYP(base, ours, theirs)
+	err = project.WriteYY(fsys, flags.Arg(1), merged)
+	if err != nil { return err }
+
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func packageArg(flags *flag.FlagSet, args []string) (*project.Package, error) {
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return nil, fmt.Errorf("%v needs the path to one .yymps", flags.Name())
+	}
+	return project.OpenPackage(flags.Arg(0))
+}
+
+func ListPackageCmd(args []string) error {
+	pkg, err := packageArg(flag.NewFlagSet("list-package", flag.ExitOnError), args)
+	if err != nil { return err }
+	defer pkg.Close()
+
+	fmt.Printf("%v %v (%v)\n", pkg.Meta.DisplayName, pkg.Meta.Version, pkg.Meta.PackageID)
+	for _, res := range pkg.Resources {
+		fmt.Printf("%v\t%v\t%v\n", res.GetKind(), res.GetName(), res.GetPath())
+	}
+	return nil
+}
+
+func ImportPackageCmd(args []string) error {
+	pkg, err := packageArg(flag.NewFlagSet("import-package", flag.ExitOnError), args)
+	if err != nil { return err }
+	defer pkg.Close()
+
+	p, err := yypProject("import-package")
+	if err != nil { return err }
+
+	skipped, err := p.ImportPackage(pkg)
+	if err != nil { return err }
+
+	for _, err := range skipped {
+		fmt.Println(err)
+	}
+	fmt.Printf("imported %v of %v resource(s) from %v\n",
+		len(pkg.Resources)-len(skipped), len(pkg.Resources), pkg.Meta.DisplayName)
+
+	if len(skipped) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func ExportPackageCmd(args []string) error {
+	flags := flag.NewFlagSet("export-package", flag.ExitOnError)
+	out := flags.String("out", "", "Path of the .yymps to write")
+	name := flags.String("name", "", "Display name of the package")
+	id := flags.String("id", "", "Package id, e.g. com.example.library (defaults to the name)")
+	version := flags.String("version", "1.0.0", "Version of the package")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: gmtc -path PROJECT.yyp export-package -out FILE.yymps RESOURCE...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if len(*out) == 0 || flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	p, err := yypProject("export-package")
+	if err != nil { return err }
+
+	meta := project.PackageMeta{
+		PackageID:   *id,
+		DisplayName: *name,
+		Version:     *version,
+		PackageType: "asset",
+	}
+	if len(meta.DisplayName) == 0 {
+		meta.DisplayName = strings.TrimSuffix(path.Base(*out), ".yymps")
+	}
+	if len(meta.PackageID) == 0 {
+		meta.PackageID = meta.DisplayName
+	}
+
+	f, err := os.Create(*out)
+	if err != nil { return err }
+
+	err = p.ExportPackage(f, meta, flags.Args())
+	if err != nil {
+		f.Close()
+		os.Remove(*out)
+		return err
+	}
+	return f.Close()
+}
+
+// CheckBuffer reports the Diagnostics of a single script of the project
+// at project_path, with that script's contents replaced by code.
+func CheckBuffer(project_path string, target string, code string) (int, error) {
+	project_path, err := fp.Abs(project_path)
+	if err != nil { return 0, err }
+	target, err = fp.Abs(target)
+	if err != nil { return 0, err }
+
+	p, err := project.LoadProjectBuffer(project.OSFS{}, project_path, target, code)
+	if err != nil { return 0, err }
+
+	scr, err := p.FindScript(target)
+	if err != nil { return 0, err }
+
+	cache, err := OpenCache()
+	if err != nil { return 0, err }
+
+	p.Jobs = *jobs
+	p.Cache = cache
+	p.Parse()
+
+	count := 0
+	for _, err := range Diagnostics(&p) {
+		if strings.HasPrefix(err.Error(), scr.ErrorPrefix()+": ") {
+			fmt.Println(err)
+			count++
+		}
+	}
+	return count, nil
+}
+
 func main() {
 	flag.Parse()
 
 	var err error
 
+	if flag.NArg() > 0 {
+		cmd, ok := commands[flag.Arg(0)]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown command %v\n", flag.Arg(0))
+			flag.Usage()
+			os.Exit(2)
+		}
+		err = cmd(flag.Args()[1:])
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if len(*buffer) > 0 {
+		if len(*filepath) == 0 || !*stdin {
+			panic("-buffer needs both -path and -stdin.")
+		}
+		text, err := ReadStdin()
+		if err != nil {
+			panic(err)
+		}
+		count, err := CheckBuffer(*filepath, *buffer, text)
+		if err != nil {
+			panic(err)
+		}
+		if count > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(*filepath) > 0 && *stdin {
 		panic("Cannot read file and stdin at the same time.")
 	}
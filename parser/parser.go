@@ -164,6 +164,9 @@ const (
 	TF_HEX
 	TF_HEX_DOLLAR
 	TF_HEX_HASH
+	// TF_MACRO marks tokens inserted by a macro expansion. Their
+	// locations are those of the macro's definition.
+	TF_MACRO
 )
 
 type Token struct {
@@ -241,13 +244,13 @@ func (s *scanner) char() byte {
 }
 
 func (s *scanner) move() {
-	s.Loc.Index++
 	if s.char() == '\n' {
 		s.Loc.Char = 0
 		s.Loc.Line++
 	} else {
 		s.Loc.Char++
 	}
+	s.Loc.Index++
 }
 
 func (s *scanner) eatBlockComments() bool {
@@ -588,8 +591,22 @@ func (ts Tokens) ExtractMacros() map[string]Macro {
 	return out
 }
 
+// InsertMacros replaces every use of a macro with its value. Macros used
+// in the value are expanded in turn, except for one that is already
+// being expanded, which is left as it is.
 func (ts Tokens) InsertMacros(macros map[string]Macro) Tokens {
+	// expanding holds the macros whose values are being scanned, and
+	// where their values end.
+	type expansion struct {
+		name string
+		end  int
+	}
+	expanding := make([]expansion, 0)
+
 	for i := 0; i < len(ts); i++ {
+		for len(expanding) > 0 && expanding[len(expanding)-1].end <= i {
+			expanding = expanding[:len(expanding)-1]
+		}
 		if ts[i].Type != T_IDENT {
 			continue
 		}
@@ -601,7 +618,20 @@ func (ts Tokens) InsertMacros(macros map[string]Macro) Tokens {
 			i += macro.RawTokensLength
 			continue
 		}
-		ts = slices.Replace(ts, i, i+1, macro.Value...)
+		if slices.ContainsFunc(expanding, func(e expansion) bool { return e.name == macro.Name }) {
+			continue
+		}
+
+		value := slices.Clone(macro.Value)
+		for j := range value {
+			value[j].Flags |= TF_MACRO
+		}
+		ts = slices.Replace(ts, i, i+1, value...)
+		for j := range expanding {
+			expanding[j].end += len(value) - 1
+		}
+		expanding = append(expanding, expansion{macro.Name, i + len(value)})
+		i--
 	}
 	return ts
 }
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// tokenLocs lists tokens with their locations, as "value line:char:index"
+// with lines and chars counted from 1.
+func tokenLocs(ts Tokens) []string {
+	out := make([]string, 0, len(ts))
+	for _, t := range ts {
+		if t.Type == T_EOF {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%v %v:%v:%v", t, t.Loc.Line+1, t.Loc.Char+1, t.Loc.Index))
+	}
+	return out
+}
+
+func TestTokenLocations(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{"a = 1;", []string{"IDENT<a> 1:1:0", "ASSIGN<=> 1:3:2", "NUM<1> 1:5:4", "SEMICOLON<;> 1:6:5"}},
+		// Tokens after a line break start the next line at char 1.
+		{"a\nbb = 2;\n\n  c", []string{
+			"IDENT<a> 1:1:0", "NL<\\n> 1:2:1",
+			"IDENT<bb> 2:1:2", "ASSIGN<=> 2:4:5", "NUM<2> 2:6:7", "SEMICOLON<;> 2:7:8", "NL<\\n> 2:8:9",
+			"NL<\\n> 3:1:10",
+			"IDENT<c> 4:3:13",
+		}},
+		// Comments spanning lines count their line breaks.
+		{"// one\n/* two\nthree */ x", []string{"NL<\\n> 1:7:6", "IDENT<x> 3:10:23"}},
+	}
+	for _, test := range tests {
+		ts, err := Pretokenize(test.src)
+		if err != nil {
+			t.Errorf("%q: %v", test.src, err)
+			continue
+		}
+		if got := tokenLocs(ts); !slices.Equal(got, test.want) {
+			t.Errorf("%q:\n\tgot  %v\n\twant %v", test.src, strings.Join(got, ", "), strings.Join(test.want, ", "))
+		}
+	}
+}
+
+func TestMacroFlags(t *testing.T) {
+	ts, err := TokenizeString("#macro SPEED 4 * 2\nx = SPEED;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"IDENT<x> 2:1:19", "ASSIGN<=> 2:3:21", "NUM<4> 1:14:13", "MUL<*> 1:16:15", "NUM<2> 1:18:17", "SEMICOLON<;> 2:10:28"}
+	if got := tokenLocs(ts); !slices.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", strings.Join(got, ", "), strings.Join(want, ", "))
+	}
+	for _, tok := range ts {
+		from_macro := tok.Type == T_NUMBER || tok.Type == T_MUL
+		if (tok.Flags&TF_MACRO != 0) != from_macro {
+			t.Errorf("%v has flags %b", tok, tok.Flags)
+		}
+	}
+}
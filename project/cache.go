@@ -0,0 +1,175 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"gmtc/parser"
+	"gmtc/utils"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Version is mixed into every cache key, so a new gmtc build never
+// trusts results cached by an older one.
+var Version = "0.1.0"
+
+// Cache stores per-script parse results on disk, keyed by a hash of
+// the script contents, the macro environment and the gmtc version.
+//
+// A nil *Cache is valid and simply doesn't cache anything.
+type Cache struct {
+	Dir string
+}
+
+func OpenCache(dir string) (*Cache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+// Clear removes every cached entry.
+func (c *Cache) Clear() error {
+	err := os.RemoveAll(c.Dir)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(c.Dir, 0o755)
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// macroEnvHash condenses the macros visible to every script into a
+// single hash. Token locations are left out, since moving a #macro
+// around doesn't change what it expands to.
+func macroEnvHash(macros map[string]parser.Macro) string {
+	names := make([]string, 0, len(macros))
+	for name := range macros {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		m := macros[name]
+		sb := strings.Builder{}
+		sb.WriteString(m.Config)
+		sb.WriteByte(':')
+		sb.WriteString(m.Name)
+		for _, t := range m.Value {
+			sb.WriteByte(' ')
+			sb.WriteString(t.Type.String())
+			sb.WriteByte('=')
+			sb.WriteString(t.Value)
+		}
+		parts = append(parts, sb.String())
+	}
+
+	return hashParts(parts...)
+}
+
+func (c *Cache) entryPath(kind string, parts ...string) string {
+	key := hashParts(append([]string{Version, kind}, parts...)...)
+	return path.Join(c.Dir, kind, key[:2], key+".json")
+}
+
+func (c *Cache) load(file string, out any) bool {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, out) == nil
+}
+
+// store writes the entry through a temporary file, so a concurrent or
+// interrupted run never sees a half-written entry.
+func (c *Cache) store(file string, value any) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(path.Dir(file), 0o755) != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(path.Dir(file), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if os.Rename(tmp.Name(), file) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// preprocess returns the macros defined by r, tokenizing it only when
+// they aren't cached yet.
+func (c *Cache) preprocess(r *ResGMScript) map[string]parser.Macro {
+	if c == nil {
+		return r.preprocess()
+	}
+
+	file := c.entryPath("macros", r.Script)
+	var macros map[string]parser.Macro
+	if c.load(file, &macros) {
+		return macros
+	}
+
+	macros = r.preprocess()
+	if len(r.Errors) == 0 {
+		c.store(file, macros)
+	}
+	return macros
+}
+
+// parse injects macros into r and builds its AST, unless the errors
+// for this exact script and macro environment are already cached.
+func (c *Cache) parse(r *ResGMScript, env string, macros map[string]parser.Macro) {
+	if c == nil {
+		r.injectMacros(macros)
+		r.parseAST()
+		return
+	}
+
+	file := c.entryPath("errors", env, r.ErrorPrefix(), r.Script)
+	var cached []string
+	if c.load(file, &cached) {
+		r.Errors = make(utils.Errors, 0, len(cached))
+		for _, msg := range cached {
+			r.Errors = r.Errors.Add(errors.New(msg))
+		}
+		r.Tokens = nil
+		r.Cached = true
+		return
+	}
+
+	// The macros may have come from the cache, in which case the
+	// script hasn't been tokenized yet.
+	if r.Tokens == nil && len(r.Errors) == 0 {
+		r.preprocess()
+	}
+	r.injectMacros(macros)
+	r.parseAST()
+
+	msgs := make([]string, len(r.Errors))
+	for i, err := range r.Errors {
+		msgs[i] = err.Error()
+	}
+	c.store(file, msgs)
+}
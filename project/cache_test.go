@@ -0,0 +1,77 @@
+package project
+
+import (
+	"fmt"
+	"testing"
+)
+
+func cachedParse(t *testing.T, c *Cache, fsys FS) Project {
+	t.Helper()
+	p := loadMemProject(t, fsys)
+	p.Cache = c
+	p.Parse()
+	return p
+}
+
+func TestCache(t *testing.T) {
+	c, err := OpenCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := memProject(
+		"scr_a", "var a = VALUE;",
+		"scr_b", "#macro VALUE 1\nvar b = ;",
+	)
+
+	p := cachedParse(t, c, fsys)
+	for _, scr := range p.Scripts() {
+		if scr.Cached {
+			t.Errorf("%v came from an empty cache", scr.GMLPath)
+		}
+	}
+	want := fmt.Sprint(p.AllErrors())
+
+	// Unchanged scripts are cache hits, with the same errors.
+	p = cachedParse(t, c, fsys)
+	for _, scr := range p.Scripts() {
+		if !scr.Cached {
+			t.Errorf("%v wasn't a cache hit", scr.GMLPath)
+		}
+	}
+	if got := fmt.Sprint(p.AllErrors()); got != want {
+		t.Errorf("got errors %v from the cache, want %v", got, want)
+	}
+
+	// ParseASTs builds what the cache hits skipped.
+	p.ParseASTs()
+	if a := p.Scripts()[0]; len(a.Ast.Children) == 0 {
+		t.Errorf("%v has no AST", a.GMLPath)
+	}
+
+	// Changing a macro invalidates the scripts that use it, even though
+	// their own code is the same.
+	fsys["scripts/scr_b/scr_b.gml"] = []byte("#macro VALUE +\nvar b = ;")
+	p = cachedParse(t, c, fsys)
+	a := p.Scripts()[0]
+	if a.Cached {
+		t.Errorf("%v was a cache hit after the macro it uses changed", a.GMLPath)
+	}
+	if len(a.Errors) == 0 {
+		t.Errorf("%v wasn't reparsed with the new macro", a.GMLPath)
+	}
+
+	// So does changing the script.
+	fsys["scripts/scr_a/scr_a.gml"] = []byte("var a = 2;")
+	p = cachedParse(t, c, fsys)
+	if a := p.Scripts()[0]; a.Cached || len(a.Errors) != 0 {
+		t.Errorf("%v: cached %v, errors %v", a.GMLPath, a.Cached, a.Errors)
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	p := loadMemProject(t, memProject("scr_a", "var a = 1;"))
+	p.Parse()
+	if scr := p.Scripts()[0]; scr.Cached || len(scr.Ast.Children) == 0 {
+		t.Errorf("a nil cache didn't parse the script")
+	}
+}
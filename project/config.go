@@ -0,0 +1,97 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ConfigFile is the name of the optional per-project configuration,
+// stored next to the .yyp.
+const ConfigFile = "gmtc.json"
+
+type Config struct {
+	Library LibraryConfig `json:"library"`
+}
+
+// LibraryConfig marks resources as third-party code. Library code is
+// still loaded and parsed, so its macros and functions are known to the
+// rest of the project, but errors in it aren't reported.
+type LibraryConfig struct {
+	// Folders are library folders, either as in the IDE
+	// ("Libraries/Scribble") or as in the .yyp
+	// ("folders/Libraries/Scribble.yy"). Subfolders are included.
+	Folders []string `json:"folders"`
+	// Patterns are path.Match patterns for library resource names,
+	// e.g. "__scribble*".
+	Patterns []string `json:"patterns"`
+	// Summary is where the symbol summary of library scripts is kept,
+	// relative to the project's directory. When it is up to date, the
+	// scripts it covers are not parsed at all.
+	Summary string `json:"summary"`
+}
+
+// LoadConfig reads the configuration of the project in dir. A project
+// without one gets the zero Config.
+func LoadConfig(fsys FS, dir string) (Config, error) {
+	var config Config
+
+	file_path := path.Join(dir, ConfigFile)
+	b, err := fsys.ReadFile(file_path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		return config, fmt.Errorf("%v: %w", file_path, err)
+	}
+
+	for _, pattern := range config.Library.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return config, fmt.Errorf("%v: bad library pattern %q: %w", file_path, pattern, err)
+		}
+	}
+
+	return config, nil
+}
+
+// libraryFolderPath turns a folder as written in the config into the
+// form the .yyp uses.
+func libraryFolderPath(folder string) string {
+	folder = strings.Trim(folder, "/")
+	if !strings.HasPrefix(folder, "folders/") {
+		folder = "folders/" + folder
+	}
+	if !strings.HasSuffix(folder, ".yy") {
+		folder += ".yy"
+	}
+	return folder
+}
+
+// IsLibrary reports whether the config marks res as library code.
+func (p *Project) IsLibrary(res Resource) bool {
+	lib := p.Config.Library
+
+	for _, pattern := range lib.Patterns {
+		if ok, _ := path.Match(pattern, res.GetName()); ok {
+			return true
+		}
+	}
+
+	parent := res.GetParent()
+	for _, folder := range lib.Folders {
+		folder = libraryFolderPath(folder)
+		if parent == folder || strings.HasPrefix(parent, strings.TrimSuffix(folder, ".yy")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
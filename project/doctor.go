@@ -0,0 +1,136 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"gmtc/utils"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+type MissingFileError struct{ error }
+type OrphanedFileError struct{ error }
+type DuplicateNameError struct{ error }
+type NameMismatchError struct{ error }
+
+// Validate checks the project for inconsistencies that GameMaker itself
+// tends to either silently tolerate or fail on much later: resources or
+// event scripts that are listed but missing, files that nothing refers
+// to, duplicate resource names and resources whose name doesn't match
+// where they are stored.
+func (p *Project) Validate() utils.Errors {
+	out := make(utils.Errors, 0)
+	out = out.Extend(p.validateMissing())
+	out = out.Extend(p.validateOrphans())
+	out = out.Extend(p.validateNames())
+	return out
+}
+
+func (p *Project) validateMissing() utils.Errors {
+	out := make(utils.Errors, 0)
+
+	for _, err := range p.Errors {
+		if errors.As(err, &LoadResourceError{}) {
+			out = out.Add(MissingFileError{fmt.Errorf(
+				"%v: listed resource cannot be loaded: %w", p.File, err,
+			)})
+		}
+	}
+
+	for _, res := range p.Resources {
+		for _, err := range res.GetErrors() {
+			if errors.As(err, &LoadResourceError{}) {
+				out = out.Add(MissingFileError{fmt.Errorf(
+					"%v: %v lists a script that cannot be loaded: %w",
+					res.GetPath(), res.GetName(), err,
+				)})
+			}
+		}
+	}
+
+	return out
+}
+
+// isResourceFile reports whether a .yy file is stored like a resource,
+// i.e. as <kind>/<name>/<name>.yy. This leaves out .yy files GameMaker
+// keeps elsewhere, like those in options/.
+func isResourceFile(file_path string) bool {
+	name := strings.TrimSuffix(path.Base(file_path), ".yy")
+	return path.Base(path.Dir(file_path)) == name
+}
+
+func (p *Project) validateOrphans() utils.Errors {
+	out := make(utils.Errors, 0)
+	if p.Kind != PK_PROJECT {
+		return out
+	}
+
+	// Rooms keep their creation code and extensions their source files
+	// next to their .yy, without listing them as scripts, so whatever is
+	// in the directory of a resource without scripts belongs to it.
+	dirs := make([]string, 0)
+	for _, res := range p.Resources {
+		if _, ok := res.(codeResource); !ok && path.Dir(res.GetPath()) != p.Root {
+			dirs = append(dirs, path.Dir(res.GetPath())+"/")
+		}
+	}
+
+	fs.WalkDir(p.FS, p.Root, func(file_path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if p.Owner(file_path) != nil || slices.Contains(p.listed, file_path) {
+			return nil
+		}
+		if slices.ContainsFunc(dirs, func(dir string) bool { return strings.HasPrefix(file_path, dir) }) {
+			return nil
+		}
+
+		switch path.Ext(file_path) {
+		case ".gml":
+			out = out.Add(OrphanedFileError{fmt.Errorf(
+				"%v: script file doesn't belong to any resource", file_path,
+			)})
+		case ".yy":
+			if isResourceFile(file_path) {
+				out = out.Add(OrphanedFileError{fmt.Errorf(
+					"%v: resource isn't listed in %v", file_path, p.File,
+				)})
+			}
+		}
+		return nil
+	})
+
+	return out
+}
+
+func (p *Project) validateNames() utils.Errors {
+	out := make(utils.Errors, 0)
+	seen := make(map[string]Resource, len(p.Resources))
+
+	for _, res := range p.Resources {
+		name := res.GetName()
+
+		if first, ok := seen[name]; ok {
+			out = out.Add(DuplicateNameError{fmt.Errorf(
+				"%v: resource name %v is already used by %v",
+				res.GetPath(), name, first.GetPath(),
+			)})
+		} else {
+			seen[name] = res
+		}
+
+		dir := path.Base(path.Dir(res.GetPath()))
+		file := strings.TrimSuffix(path.Base(res.GetPath()), ".yy")
+		if dir != name || file != name {
+			out = out.Add(NameMismatchError{fmt.Errorf(
+				"%v: resource is called %v but is stored as %v/%v.yy",
+				res.GetPath(), name, dir, file,
+			)})
+		}
+	}
+
+	return out
+}
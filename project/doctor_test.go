@@ -0,0 +1,64 @@
+package project
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateClean(t *testing.T) {
+	p, err := LoadProject("testdata/proj/proj.yyp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := p.Validate(); len(errs) != 0 {
+		t.Errorf("got problems in a valid project:\n%v", errs.Merge())
+	}
+}
+
+// readTestProject copies a project in testdata into memory.
+func readTestProject(t *testing.T, root string) MemFS {
+	t.Helper()
+	fsys := MemFS{}
+	err := fs.WalkDir(os.DirFS(root), ".", func(file_path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		fsys[file_path], err = os.ReadFile(path.Join(root, file_path))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys
+}
+
+func TestValidateOrphans(t *testing.T) {
+	fsys := readTestProject(t, "testdata/proj")
+	// An event the object doesn't list, and a script nothing lists.
+	fsys["objects/obj_a/Step_0.gml"] = []byte("x += 1;")
+	fsys["scripts/scr_gone/scr_gone.gml"] = []byte("")
+	fsys["scripts/scr_gone/scr_gone.yy"] = fsys["scripts/scr_a/scr_a.yy"]
+	p, err := LoadProjectFS(fsys, "proj.yyp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, 0)
+	for _, err := range p.Validate() {
+		if !errors.As(err, &OrphanedFileError{}) {
+			t.Errorf("unexpected problem: %v", err)
+		}
+		file, _, _ := strings.Cut(err.Error(), ":")
+		got = append(got, file)
+	}
+	slices.Sort(got)
+	want := []string{"objects/obj_a/Step_0.gml", "scripts/scr_gone/scr_gone.gml", "scripts/scr_gone/scr_gone.yy"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got orphans %v, want %v", got, want)
+	}
+}
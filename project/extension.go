@@ -0,0 +1,96 @@
+package project
+
+import (
+	"github.com/tidwall/gjson"
+)
+
+// EXT_TYPE mirrors the numeric argument/return types GameMaker stores
+// in extension function declarations.
+type EXT_TYPE int
+
+//go:generate stringer -type=EXT_TYPE -linecomment
+const (
+	ET_UNKNOWN EXT_TYPE = 0 // Unknown
+	ET_STRING  EXT_TYPE = 1 // String
+	ET_REAL    EXT_TYPE = 2 // Real
+)
+
+type ExtFunction struct {
+	Name         string
+	ExternalName string
+	Help         string
+	Extension    string
+	File         string
+	Args         []EXT_TYPE
+	Return       EXT_TYPE
+	// Variadic is set for functions declared with an argCount of -1,
+	// in which case Args is meaningless.
+	Variadic bool
+	Hidden   bool
+}
+
+type ExtConstant struct {
+	Name      string
+	Value     string
+	Extension string
+	Hidden    bool
+}
+
+type ResGMExtension struct {
+	BaseResource
+	Functions []ExtFunction
+	Constants []ExtConstant
+}
+
+func loadExtFunction(ext string, file string, data gjson.Result) ExtFunction {
+	data_map := data.Map()
+
+	args_json := data_map["args"].Array()
+	args := make([]EXT_TYPE, 0, len(args_json))
+	for _, arg := range args_json {
+		args = append(args, EXT_TYPE(arg.Int()))
+	}
+
+	return ExtFunction{
+		Name:         data_map["name"].Str,
+		ExternalName: data_map["externalName"].Str,
+		Help:         data_map["help"].Str,
+		Extension:    ext,
+		File:         file,
+		Args:         args,
+		Return:       EXT_TYPE(data_map["returnType"].Int()),
+		Variadic:     data_map["argCount"].Int() < 0,
+		Hidden:       data_map["hidden"].Bool(),
+	}
+}
+
+func loadGMExtension(base BaseResource, data gjson.Result) ResGMExtension {
+	data_map := data.Map()
+	name := base.Name
+
+	functions := make([]ExtFunction, 0)
+	constants := make([]ExtConstant, 0)
+
+	for _, file_json := range data_map["files"].Array() {
+		file_name := file_json.Get("filename").Str
+
+		for _, fn_json := range file_json.Get("functions").Array() {
+			functions = append(functions, loadExtFunction(name, file_name, fn_json))
+		}
+
+		for _, c_json := range file_json.Get("constants").Array() {
+			constants = append(constants, ExtConstant{
+				Name:      c_json.Get("name").Str,
+				Value:     c_json.Get("value").Str,
+				Extension: name,
+				Hidden:    c_json.Get("hidden").Bool(),
+			})
+		}
+	}
+
+	return ResGMExtension{
+		BaseResource: base,
+		Functions:    functions,
+		Constants:    constants,
+	}
+}
@@ -0,0 +1,93 @@
+package project
+
+import (
+	"fmt"
+	"github.com/tidwall/gjson"
+	"strings"
+)
+
+// GameMaker has changed its project format a few times. 2.3 introduced
+// the layout loaded here, with a list of {"id": {name, path}} references
+// in the .yyp; LTS releases still use it. Releases since 2024 keep the
+// layout but add "$GM<kind>" and "%Name" keys to every resource and bump
+// resourceVersion to 2.0. Projects from before 2.3 store resources by
+// UUID and are not supported.
+
+type PROJECT_FORMAT int
+
+const (
+	PF_UNKNOWN PROJECT_FORMAT = iota
+	PF_GMS22
+	PF_GMS23
+	PF_GM2024
+)
+
+func (f PROJECT_FORMAT) String() string {
+	switch f {
+	case PF_GMS22:
+		return "GameMaker Studio 2.2"
+	case PF_GMS23:
+		return "GameMaker Studio 2.3 / LTS"
+	case PF_GM2024:
+		return "GameMaker 2024"
+	}
+	return "unknown"
+}
+
+type UnsupportedFormatError struct{ error }
+
+// DetectFormat works out which format a .yyp is in. Formats that can't
+// be loaded are reported as an UnsupportedFormatError.
+func DetectFormat(data gjson.Result) (PROJECT_FORMAT, error) {
+	version := data.Get("resourceVersion").Str
+	major, _, _ := strings.Cut(version, ".")
+
+	switch {
+	case data.Get("mvc").Exists() || data.Get("resources.0.Key").Exists():
+		return PF_GMS22, UnsupportedFormatError{fmt.Errorf(
+			"projects from %v and older aren't supported, open and save the project in a newer GameMaker first",
+			PF_GMS22,
+		)}
+
+	case data.Get("resourceType").Str != "GMProject":
+		return PF_UNKNOWN, UnsupportedFormatError{fmt.Errorf("not a GameMaker project file")}
+
+	case major == "1":
+		return PF_GMS23, nil
+
+	case major == "2":
+		return PF_GM2024, nil
+	}
+
+	return PF_UNKNOWN, UnsupportedFormatError{fmt.Errorf(
+		"unknown project resourceVersion %q (IDE version %v), gmtc %v supports %v and %v",
+		version, data.Get("MetaData.IDEVersion").Str, Version, PF_GMS23, PF_GM2024,
+	)}
+}
+
+// resourceKind returns the type of a resource, which every format
+// stores in resourceType. Newer ones also have a "$GM<kind>" key, which
+// is used if resourceType is missing.
+func resourceKind(data gjson.Result) string {
+	if kind := data.Get("resourceType").Str; kind != "" {
+		return kind
+	}
+	kind := ""
+	data.ForEach(func(key, _ gjson.Result) bool {
+		if strings.HasPrefix(key.Str, "$GM") {
+			kind = key.Str[1:]
+			return false
+		}
+		return true
+	})
+	return kind
+}
+
+// resourceName returns the name of a resource, from "name" or, in newer
+// formats, "%Name".
+func resourceName(data gjson.Result) string {
+	if name := data.Get("name").Str; name != "" {
+		return name
+	}
+	return data.Get("%Name").Str
+}
@@ -0,0 +1,280 @@
+package project
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FS is the file system a project is read from.
+//
+// Unlike a plain fs.FS, names are used exactly as the project builds
+// them (joined onto the directory of the .yyp), so they may be absolute
+// or relative to the working directory.
+type FS interface {
+	fs.FS
+	fs.ReadFileFS
+	fs.StatFS
+	fs.ReadDirFS
+}
+
+// OSFS reads straight from the operating system's file system.
+type OSFS struct{}
+
+func (OSFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (OSFS) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (OSFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+// MemFS is an in-memory file system, keyed by file path. Directories
+// exist implicitly wherever a file path has them as a prefix.
+type MemFS map[string][]byte
+
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+type memFile struct {
+	info memInfo
+	data []byte
+	off  int
+	dir  []fs.DirEntry
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+func (f *memFile) Read(b []byte) (int, error) {
+	if f.off >= len(f.data) {
+		return 0, io.EOF
+	}
+	n := copy(b, f.data[f.off:])
+	f.off += n
+	return n, nil
+}
+
+func (f *memFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		out := f.dir
+		f.dir = nil
+		return out, nil
+	}
+	if len(f.dir) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(f.dir))
+	out := f.dir[:n]
+	f.dir = f.dir[n:]
+	return out, nil
+}
+
+func memClean(name string) string {
+	return path.Clean(name)
+}
+
+func (m MemFS) Open(name string) (fs.File, error) {
+	name = memClean(name)
+	if data, ok := m[name]; ok {
+		return &memFile{
+			info: memInfo{path.Base(name), int64(len(data)), false},
+			data: data,
+		}, nil
+	}
+
+	entries, err := m.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{
+		info: memInfo{path.Base(name), 0, true},
+		dir:  entries,
+	}, nil
+}
+
+func (m MemFS) ReadFile(name string) ([]byte, error) {
+	data, ok := m[memClean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return slices.Clone(data), nil
+}
+
+func (m MemFS) Stat(name string) (fs.FileInfo, error) {
+	f, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.Stat()
+}
+
+func (m MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	name = memClean(name)
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	} else if name == "/" {
+		prefix = "/"
+	}
+
+	seen := make(map[string]bool)
+	entries := make([]fs.DirEntry, 0)
+	for file, data := range m {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+		rest := file[len(prefix):]
+		child, _, is_dir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		info := memInfo{child, int64(len(data)), is_dir}
+		if is_dir {
+			info.size = 0
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+
+	if len(entries) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// ZipFS reads from a zip archive. Names are relative to the root of the
+// archive.
+type ZipFS struct{ *zip.Reader }
+
+func (z ZipFS) ReadFile(name string) ([]byte, error)       { return fs.ReadFile(z.Reader, name) }
+func (z ZipFS) Stat(name string) (fs.FileInfo, error)      { return fs.Stat(z.Reader, name) }
+func (z ZipFS) ReadDir(name string) ([]fs.DirEntry, error) { return fs.ReadDir(z.Reader, name) }
+
+// OverlayFS reads from Files where they have an entry, and from Base
+// everywhere else. It lets a project be loaded with some of its files
+// replaced, e.g. by an editor's unsaved buffers.
+type OverlayFS struct {
+	Base  FS
+	Files MemFS
+}
+
+func (o OverlayFS) Open(name string) (fs.File, error) {
+	if _, ok := o.Files[memClean(name)]; ok {
+		return o.Files.Open(name)
+	}
+	info, err := o.Stat(name)
+	if err == nil && info.IsDir() {
+		entries, err := o.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		return &memFile{
+			info: memInfo{path.Base(name), 0, true},
+			dir:  entries,
+		}, nil
+	}
+	return o.Base.Open(name)
+}
+
+func (o OverlayFS) ReadFile(name string) ([]byte, error) {
+	if _, ok := o.Files[memClean(name)]; ok {
+		return o.Files.ReadFile(name)
+	}
+	return o.Base.ReadFile(name)
+}
+
+func (o OverlayFS) Stat(name string) (fs.FileInfo, error) {
+	if info, err := o.Files.Stat(name); err == nil {
+		return info, nil
+	}
+	return o.Base.Stat(name)
+}
+
+func (o OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	base, base_err := o.Base.ReadDir(name)
+	over, over_err := o.Files.ReadDir(name)
+	if base_err != nil && over_err != nil {
+		return nil, base_err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(base)+len(over))
+	entries = append(entries, over...)
+	for _, e := range base {
+		shadowed := slices.ContainsFunc(over, func(o fs.DirEntry) bool {
+			return o.Name() == e.Name()
+		})
+		if !shadowed {
+			entries = append(entries, e)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// WriteFS is a file system project files can also be written to.
+type WriteFS interface {
+	FS
+	// WriteFile creates or replaces the file, along with any missing
+	// parent directories.
+	WriteFile(name string, data []byte) error
+	Remove(name string) error
+}
+
+func (OSFS) WriteFile(name string, data []byte) error {
+	err := os.MkdirAll(path.Dir(name), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
+
+func (OSFS) Remove(name string) error { return os.Remove(name) }
+
+func (m MemFS) WriteFile(name string, data []byte) error {
+	m[memClean(name)] = slices.Clone(data)
+	return nil
+}
+
+func (m MemFS) Remove(name string) error {
+	name = memClean(name)
+	if _, ok := m[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m, name)
+	return nil
+}
+
+// WriteFile writes to the overlay, leaving Base untouched.
+func (o OverlayFS) WriteFile(name string, data []byte) error {
+	return o.Files.WriteFile(name, data)
+}
+
+func (o OverlayFS) Remove(name string) error {
+	return o.Files.Remove(name)
+}
@@ -0,0 +1,124 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// memProject builds a project of scripts in memory. scripts alternates
+// names and code.
+func memProject(scripts ...string) MemFS {
+	fsys := MemFS{}
+	resources := make([]string, 0)
+	for i := 0; i+1 < len(scripts); i += 2 {
+		name, code := scripts[i], scripts[i+1]
+		yy := "scripts/" + name + "/" + name + ".yy"
+		resources = append(resources, fmt.Sprintf(`{"id":{"name":%q,"path":%q},"order":0}`, name, yy))
+		fsys[yy] = []byte(fmt.Sprintf(`{"name":%q,"resourceType":"GMScript","resourceVersion":"1.0"}`, name))
+		fsys[strings.TrimSuffix(yy, ".yy")+".gml"] = []byte(code)
+	}
+	fsys["p.yyp"] = []byte(fmt.Sprintf(
+		`{"resources":[%v],"Folders":[],"name":"p","resourceType":"GMProject","resourceVersion":"1.4"}`,
+		strings.Join(resources, ","),
+	))
+	return fsys
+}
+
+func loadMemProject(t *testing.T, fsys FS) Project {
+	t.Helper()
+	p, err := LoadProjectFS(fsys, "p.yyp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMemFS(t *testing.T) {
+	fsys := MemFS{
+		"a/b.gml":   []byte("x"),
+		"a/c/d.gml": []byte("yy"),
+	}
+
+	b, err := fsys.ReadFile("a/./b.gml")
+	if err != nil || string(b) != "x" {
+		t.Errorf("got %q, %v", b, err)
+	}
+	if _, err := fsys.ReadFile("a/missing.gml"); err == nil {
+		t.Errorf("missing file was read")
+	}
+
+	entries, err := fsys.ReadDir("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = fmt.Sprintf("%v %v", e.Name(), e.IsDir())
+	}
+	if !slices.Equal(names, []string{"b.gml false", "c true"}) {
+		t.Errorf("got entries %v", names)
+	}
+
+	files := make([]string, 0)
+	fs.WalkDir(fsys, ".", func(file_path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, file_path)
+		}
+		return nil
+	})
+	if !slices.Equal(files, []string{"a/b.gml", "a/c/d.gml"}) {
+		t.Errorf("walked %v", files)
+	}
+}
+
+func TestOverlayFS(t *testing.T) {
+	base := MemFS{
+		"a/b.gml": []byte("base"),
+		"a/c.gml": []byte("base"),
+	}
+	fsys := OverlayFS{Base: base, Files: MemFS{
+		"a/b.gml": []byte("over"),
+		"a/d.gml": []byte("new"),
+	}}
+
+	for name, want := range map[string]string{"a/b.gml": "over", "a/c.gml": "base", "a/d.gml": "new"} {
+		if b, err := fsys.ReadFile(name); err != nil || string(b) != want {
+			t.Errorf("%v: got %q, %v", name, b, err)
+		}
+	}
+
+	entries, err := fsys.ReadDir("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	if !slices.Equal(names, []string{"b.gml", "c.gml", "d.gml"}) {
+		t.Errorf("got entries %v", names)
+	}
+
+	fsys.WriteFile("a/c.gml", []byte("written"))
+	if string(base["a/c.gml"]) != "base" {
+		t.Errorf("writing changed the base")
+	}
+}
+
+func TestLoadProjectBuffer(t *testing.T) {
+	fsys := memProject("scr_a", "var a = 1;")
+	p, err := LoadProjectBuffer(fsys, "p.yyp", "scripts/scr_a/scr_a.gml", "var a = ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Parse()
+	if p.ErrorCount() == 0 {
+		t.Errorf("the buffer wasn't checked instead of the file")
+	}
+	if string(fsys["scripts/scr_a/scr_a.gml"]) != "var a = 1;" {
+		t.Errorf("the file was changed")
+	}
+}
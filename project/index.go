@@ -0,0 +1,133 @@
+package project
+
+import (
+	"path"
+	"strings"
+)
+
+type Folder struct {
+	Name string
+	// Path is the folder's folderPath, e.g. "folders/Scripts/UI.yy".
+	Path  string
+	Order int
+}
+
+// ParentPath returns the path of the folder containing f, or "" if f is
+// at the top of the project.
+func (f Folder) ParentPath() string {
+	dir := path.Dir(strings.TrimSuffix(f.Path, ".yy"))
+	if dir == "folders" || dir == "." {
+		return ""
+	}
+	return dir + ".yy"
+}
+
+type index struct {
+	by_name map[string]Resource
+	by_file map[string]Resource
+	by_kind map[string][]Resource
+	folders map[string]Folder
+}
+
+func buildIndex(p *Project) *index {
+	idx := &index{
+		by_name: make(map[string]Resource, len(p.Resources)),
+		by_file: make(map[string]Resource, len(p.Resources)),
+		by_kind: make(map[string][]Resource),
+		folders: make(map[string]Folder, len(p.Folders)),
+	}
+
+	for _, res := range p.Resources {
+		// Names should be unique, but if they aren't, the first
+		// resource wins, just like it would in GameMaker.
+		if _, ok := idx.by_name[res.GetName()]; !ok {
+			idx.by_name[res.GetName()] = res
+		}
+		idx.by_kind[res.GetKind()] = append(idx.by_kind[res.GetKind()], res)
+		idx.by_file[res.GetPath()] = res
+
+		if cr, ok := res.(codeResource); ok {
+			for _, scr := range cr.Scripts() {
+				idx.by_file[scr.GMLPath] = res
+			}
+		}
+	}
+
+	for _, f := range p.Folders {
+		idx.folders[f.Path] = f
+	}
+
+	return idx
+}
+
+func (p *Project) idx() *index {
+	if p.index == nil {
+		p.index = buildIndex(p)
+	}
+	return p.index
+}
+
+// Resource returns the resource called name, or nil.
+func (p *Project) Resource(name string) Resource {
+	return p.idx().by_name[name]
+}
+
+// ResourceOfKind returns the resource called name if it is of the given
+// kind (e.g. "GMObject"), or nil.
+func (p *Project) ResourceOfKind(kind string, name string) Resource {
+	res := p.Resource(name)
+	if res == nil || res.GetKind() != kind {
+		return nil
+	}
+	return res
+}
+
+// ResourcesOfKind returns every resource of the given kind, in project
+// order.
+func (p *Project) ResourcesOfKind(kind string) []Resource {
+	return p.idx().by_kind[kind]
+}
+
+// Owner returns the resource that file_path belongs to, either as its
+// .yy file or as one of its .gml files (scripts, events or moments).
+// file_path may be given as-is or relative to the project's directory.
+func (p *Project) Owner(file_path string) Resource {
+	idx := p.idx()
+	if res, ok := idx.by_file[path.Clean(file_path)]; ok {
+		return res
+	}
+	return idx.by_file[path.Join(p.Root, file_path)]
+}
+
+// Folder returns the folder with the given folderPath.
+func (p *Project) Folder(folder_path string) (Folder, bool) {
+	f, ok := p.idx().folders[folder_path]
+	return f, ok
+}
+
+// FolderContents returns the folders and resources directly inside the
+// folder at folder_path. An empty folder_path stands for the top of the
+// project's folder tree.
+func (p *Project) FolderContents(folder_path string) ([]Folder, []Resource) {
+	idx := p.idx()
+
+	folders := make([]Folder, 0)
+	for _, f := range p.Folders {
+		if f.ParentPath() == folder_path {
+			folders = append(folders, f)
+		}
+	}
+
+	resources := make([]Resource, 0)
+	for _, res := range p.Resources {
+		parent := res.GetParent()
+		if _, ok := idx.folders[parent]; !ok {
+			parent = ""
+		}
+		if parent == folder_path {
+			resources = append(resources, res)
+		}
+	}
+
+	return folders, resources
+}
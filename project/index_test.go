@@ -0,0 +1,61 @@
+package project
+
+import (
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	fsys := memProject("scr_a", "var a = 1;", "scr_b", "var b = 1;")
+	fsys["scripts/scr_b/scr_b.yy"] = []byte(`{"name":"scr_b","resourceType":"GMScript","resourceVersion":"1.0",
+		"parent":{"name":"UI","path":"folders/Scripts/UI.yy"}}`)
+	fsys["objects/obj_a/obj_a.yy"] = []byte(`{"name":"obj_a","resourceType":"GMObject","resourceVersion":"1.0",
+		"eventList":[{"eventNum":0,"eventType":3,"resourceType":"GMEvent"}]}`)
+	fsys["objects/obj_a/Step_0.gml"] = []byte("x += 1;")
+	fsys["p.yyp"] = []byte(`{"resources":[
+		{"id":{"name":"scr_a","path":"scripts/scr_a/scr_a.yy"}},
+		{"id":{"name":"scr_b","path":"scripts/scr_b/scr_b.yy"}},
+		{"id":{"name":"obj_a","path":"objects/obj_a/obj_a.yy"}}],
+		"Folders":[
+		{"folderPath":"folders/Scripts.yy","order":1,"name":"Scripts"},
+		{"folderPath":"folders/Scripts/UI.yy","order":2,"name":"UI"}],
+		"name":"p","resourceType":"GMProject","resourceVersion":"1.4"}`)
+	p := loadMemProject(t, fsys)
+
+	if res := p.Resource("obj_a"); res == nil || res.GetKind() != "GMObject" {
+		t.Errorf("Resource(obj_a) is %v", res)
+	}
+	if res := p.Resource("obj_missing"); res != nil {
+		t.Errorf("Resource(obj_missing) is %v", res)
+	}
+	if res := p.ResourceOfKind("GMScript", "obj_a"); res != nil {
+		t.Errorf("ResourceOfKind(GMScript, obj_a) is %v", res)
+	}
+	if got := p.ResourcesOfKind("GMScript"); len(got) != 2 || got[0].GetName() != "scr_a" || got[1].GetName() != "scr_b" {
+		t.Errorf("ResourcesOfKind(GMScript) is %v", got)
+	}
+
+	owners := map[string]string{
+		"objects/obj_a/Step_0.gml":   "obj_a",
+		"objects/obj_a/obj_a.yy":     "obj_a",
+		"./scripts/scr_a/scr_a.gml":  "scr_a",
+		"objects/obj_a/Create_0.gml": "",
+	}
+	for file, want := range owners {
+		got := ""
+		if res := p.Owner(file); res != nil {
+			got = res.GetName()
+		}
+		if got != want {
+			t.Errorf("Owner(%v) is %q, want %q", file, got, want)
+		}
+	}
+
+	folders, resources := p.FolderContents("")
+	if len(folders) != 1 || folders[0].Name != "Scripts" || len(resources) != 2 {
+		t.Errorf("top level has folders %v and resources %v", folders, resources)
+	}
+	folders, resources = p.FolderContents("folders/Scripts/UI.yy")
+	if len(folders) != 0 || len(resources) != 1 || resources[0].GetName() != "scr_b" {
+		t.Errorf("UI has folders %v and resources %v", folders, resources)
+	}
+}
@@ -0,0 +1,345 @@
+package project
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"gmtc/parser"
+	"gmtc/utils"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Local packages (.yymps) are zip archives of resource directories, laid
+// out as they are in a project, plus a metadata.json. They have no .yyp
+// of their own: resources are added to the project they are imported
+// into, along with whatever folders they were in.
+
+type PackageMeta struct {
+	PackageID   string `json:"package_id"`
+	DisplayName string `json:"display_name"`
+	Version     string `json:"version"`
+	PackageType string `json:"package_type"`
+	IDEVersion  string `json:"ide_version"`
+}
+
+const packageMetaFile = "metadata.json"
+
+type Package struct {
+	Meta      PackageMeta
+	FS        FS
+	Resources []Resource
+	closer    io.Closer
+}
+
+// OpenPackage opens the .yymps at file_path and loads the resources in
+// it. The package must be closed once it is no longer needed.
+func OpenPackage(file_path string) (*Package, error) {
+	zr, err := zip.OpenReader(file_path)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := LoadPackage(ZipFS{&zr.Reader})
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	pkg.closer = zr
+	return pkg, nil
+}
+
+// LoadPackage loads a package from the root of fsys.
+func LoadPackage(fsys FS) (*Package, error) {
+	pkg := &Package{FS: fsys}
+
+	b, err := fsys.ReadFile(packageMetaFile)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &pkg.Meta)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", packageMetaFile, err)
+	}
+
+	err = fs.WalkDir(fsys, ".", func(file_path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(file_path) != ".yy" || !isResourceFile(file_path) {
+			return nil
+		}
+		res, err := loadResource(fsys, file_path)
+		if err != nil {
+			return err
+		}
+		pkg.Resources = append(pkg.Resources, res)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+func (pkg *Package) Close() error {
+	if pkg.closer == nil {
+		return nil
+	}
+	return pkg.closer.Close()
+}
+
+// ImportPackage copies the resources of a package into the project and
+// lists them in the .yyp. Resources whose name is already taken, in the
+// project or by another resource of the package, or whose files are
+// already there, are skipped and reported as ResourceExistsErrors;
+// everything else is imported.
+func (p *Project) ImportPackage(pkg *Package) (utils.Errors, error) {
+	skipped := make(utils.Errors, 0)
+
+	wfs, err := p.writeFS()
+	if err != nil {
+		return skipped, err
+	}
+	yyp, err := ReadYY(p.FS, p.File)
+	if err != nil {
+		return skipped, err
+	}
+
+	imported := make(map[string]Resource)
+	for _, res := range pkg.Resources {
+		name := res.GetName()
+		res_path := res.GetPath()
+
+		if other, ok := imported[name]; ok {
+			skipped = skipped.Add(ResourceExistsError{fmt.Errorf(
+				"%v is in the package twice (%v), not importing %v", name, other.GetPath(), res_path,
+			)})
+			continue
+		}
+		imported[name] = res
+
+		if other := p.Resource(name); other != nil {
+			skipped = skipped.Add(ResourceExistsError{fmt.Errorf(
+				"%v already exists (%v), not importing it", name, other.GetPath(),
+			)})
+			continue
+		}
+		if _, err := p.FS.Stat(path.Join(p.Root, res_path)); err == nil {
+			skipped = skipped.Add(ResourceExistsError{fmt.Errorf(
+				"%v is already there, not importing %v", res_path, name,
+			)})
+			continue
+		}
+
+		folder_path := res.GetParent()
+		if !strings.HasPrefix(folder_path, "folders/") {
+			folder_path = ""
+		}
+
+		err = fs.WalkDir(pkg.FS, path.Dir(res_path), func(file_path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if file_path == res_path {
+				return nil
+			}
+			b, err := pkg.FS.ReadFile(file_path)
+			if err != nil {
+				return err
+			}
+			return wfs.WriteFile(path.Join(p.Root, file_path), b)
+		})
+		if err != nil {
+			return skipped, err
+		}
+
+		// The parent refers to a folder of the project the package was
+		// made from, or to that project itself.
+		doc, err := ReadYY(pkg.FS, res_path)
+		if err != nil {
+			return skipped, err
+		}
+		parent := p.parentRef(yyp, folder_path)
+		if old := doc.Root.Get("parent"); old != nil {
+			parent.Inline = old.Inline
+		}
+		doc.Root.Set("parent", parent)
+		err = WriteYY(wfs, path.Join(p.Root, res_path), doc)
+		if err != nil {
+			return skipped, err
+		}
+
+		err = p.addEntry(yyp, name, res_path, folder_path)
+		if err != nil {
+			return skipped, err
+		}
+	}
+
+	err = WriteYY(wfs, p.File, yyp)
+	if err != nil {
+		return skipped, err
+	}
+	return skipped, p.Reload()
+}
+
+// Dependencies returns the named resources along with every resource
+// they depend on, directly or not. A resource depends on the resources
+// its .yy file refers to (an object's sprite and parent, ...) and on the
+// resources and script functions named in its code.
+func (p *Project) Dependencies(names []string) ([]Resource, error) {
+	funcs := p.ScriptFunctions()
+	out := make([]Resource, 0, len(names))
+	seen := make(map[Resource]bool)
+
+	var add func(res Resource) error
+	add = func(res Resource) error {
+		if seen[res] {
+			return nil
+		}
+		seen[res] = true
+		out = append(out, res)
+
+		deps, err := p.dependenciesOf(res, funcs)
+		if err != nil {
+			return err
+		}
+		for _, dep := range deps {
+			err = add(dep)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		res := p.Resource(name)
+		if res == nil {
+			return nil, fmt.Errorf("no resource called %v", name)
+		}
+		err := add(res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// ScriptFunctions maps the names of the functions declared at the top of
+// scripts to the script that declares them.
+func (p *Project) ScriptFunctions() map[string]Resource {
+	out := make(map[string]Resource)
+	for _, res := range p.ResourcesOfKind("GMScript") {
+		for _, name := range res.(*ResGMScript).functions() {
+			out[name] = res
+		}
+	}
+	return out
+}
+
+func (p *Project) dependenciesOf(res Resource, funcs map[string]Resource) ([]Resource, error) {
+	deps := make([]Resource, 0)
+	add := func(dep Resource) {
+		if dep != nil && dep != res && !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+
+	doc, err := ReadYY(p.FS, res.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	var refs func(v *YYValue, key string)
+	refs = func(v *YYValue, key string) {
+		if key == "parent" {
+			return
+		}
+		if v.Kind == YY_OBJECT && v.Has("name") && strings.HasSuffix(v.Get("path").Str(), ".yy") {
+			add(p.Owner(v.Get("path").Str()))
+			return
+		}
+		for _, f := range v.Fields {
+			refs(f.Value, f.Key)
+		}
+		for _, it := range v.Items {
+			refs(it, "")
+		}
+	}
+	refs(doc.Root, "")
+
+	if cr, ok := res.(codeResource); ok {
+		for _, scr := range cr.Scripts() {
+			ts, err := parser.Pretokenize(scr.Script)
+			if err != nil {
+				continue
+			}
+			for _, t := range ts {
+				if t.Type != parser.T_IDENT {
+					continue
+				}
+				if dep, ok := funcs[t.Value]; ok {
+					add(dep)
+				} else {
+					add(p.Resource(t.Value))
+				}
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// ExportPackage writes the named resources and their dependencies to w
+// as a .yymps.
+func (p *Project) ExportPackage(w io.Writer, meta PackageMeta, names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no resources to export")
+	}
+	resources, err := p.Dependencies(names)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	mw, err := zw.Create(packageMetaFile)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(mw).Encode(meta)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range resources {
+		if !isResourceFile(res.GetPath()) {
+			return fmt.Errorf("%v isn't stored like a resource, can't export it", res.GetPath())
+		}
+		err = fs.WalkDir(p.FS, path.Dir(res.GetPath()), func(file_path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			b, err := p.FS.ReadFile(file_path)
+			if err != nil {
+				return err
+			}
+			fw, err := zw.Create(p.relPath(file_path))
+			if err != nil {
+				return err
+			}
+			_, err = fw.Write(b)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -6,7 +6,6 @@ import (
 	"gmtc/ast"
 	"gmtc/parser"
 	"gmtc/utils"
-	"os"
 	"path"
 	"strings"
 )
@@ -21,15 +20,41 @@ const (
 
 type Project struct {
 	Kind      PROJECT_KIND
+	// FS is where the project's files are read from.
+	FS        FS
 	Root      string
 	File      string
 	Resources []Resource
 	Macros    map[string]parser.Macro
 	Errors    utils.Errors
+	// Jobs is the number of workers Parse may use. Values below 1 are
+	// treated as 1.
+	Jobs int
+	// Cache, when not nil, lets Parse skip scripts that are unchanged
+	// since the last run.
+	Cache *Cache
+	// Folders is the project's folder tree, as listed in the .yyp.
+	Folders []Folder
+	// Format is the version of GameMaker's file format the .yyp is in,
+	// and IDEVersion the version of GameMaker that last saved it.
+	Format     PROJECT_FORMAT
+	IDEVersion string
+	// Config is read from the gmtc.json next to the .yyp, if there is
+	// one. Summary is the summary of library scripts it points to.
+	Config  Config
+	Summary *Summary
+	index   *index
+	// listed holds the path of every resource the .yyp lists, including
+	// those that failed to load.
+	listed []string
 }
 
 func SingleFile(file_path string) Project {
-	script, err := loadGMScript(file_path)
+	return SingleFileFS(OSFS{}, file_path)
+}
+
+func SingleFileFS(fsys FS, file_path string) Project {
+	script, err := loadGMScript(fsys, file_path)
 	var errs []error = nil
 	if err != nil {
 		errs = append(errs, err)
@@ -37,6 +62,7 @@ func SingleFile(file_path string) Project {
 
 	return Project{
 		Kind:      PK_SCRIPT,
+		FS:        fsys,
 		Root:      file_path,
 		File:      file_path,
 		Resources: []Resource{&script},
@@ -48,12 +74,13 @@ func SingleFile(file_path string) Project {
 func CodeProject(name string, code string) Project {
 	return Project{
 		Kind: PK_CODE,
+		FS:   MemFS{name: []byte(code)},
 		Root: name,
 		File: name,
 		Resources: []Resource{&ResGMScript{
-			BaseResource: BaseResource{ name },
+			BaseResource: BaseResource{Path: name, Name: name, Kind: "GMScript"},
 			GMLPath: name,
-			Script: name,
+			Script: code,
 
 		}},
 		Macros: make(map[string]parser.Macro),
@@ -62,22 +89,33 @@ func CodeProject(name string, code string) Project {
 }
 
 func LoadProject(file_path string) (Project, error) {
+	return LoadProjectFS(OSFS{}, file_path)
+}
+
+func LoadProjectFS(fsys FS, file_path string) (Project, error) {
 	root_dir := path.Dir(file_path)
 
-	b, err := os.ReadFile(file_path)
+	b, err := fsys.ReadFile(file_path)
 	if err != nil {
 		return Project{}, err
 	}
 
 	proj_json := gjson.ParseBytes(b)
 
+	format, err := DetectFormat(proj_json)
+	if err != nil {
+		return Project{}, fmt.Errorf("%v: %w", file_path, err)
+	}
+
 	resource_paths := proj_json.Get("resources.#.id.path").Array()
 	resources := make([]Resource, 0, len(resource_paths))
+	listed := make([]string, 0, len(resource_paths))
 	proj_errors := make(utils.Errors, 0)
 
 	for _, res_path := range resource_paths {
 		res_fullpath := path.Join(root_dir, res_path.Str)
-		resource, load_err := loadResource(res_fullpath)
+		listed = append(listed, res_fullpath)
+		resource, load_err := loadResource(fsys, res_fullpath)
 		if load_err != nil {
 			proj_errors = proj_errors.Add(load_err)
 			continue
@@ -85,127 +123,328 @@ func LoadProject(file_path string) (Project, error) {
 		resources = append(resources, resource)
 	}
 
+	folders_json := proj_json.Get("Folders").Array()
+	folders := make([]Folder, 0, len(folders_json))
+	for _, f := range folders_json {
+		folders = append(folders, Folder{
+			Name:  resourceName(f),
+			Path:  f.Get("folderPath").Str,
+			Order: int(f.Get("order").Int()),
+		})
+	}
+
+	config, err := LoadConfig(fsys, root_dir)
+	if err != nil {
+		return Project{}, err
+	}
+
+	var summary *Summary
+	if config.Library.Summary != "" {
+		summary, err = LoadSummary(fsys, path.Join(root_dir, config.Library.Summary))
+		if err != nil {
+			return Project{}, err
+		}
+	}
+
 	return Project{
-		Root:      root_dir,
-		File:      file_path,
-		Resources: resources,
+		FS:         fsys,
+		Root:       root_dir,
+		File:       file_path,
+		Resources:  resources,
+		Folders:    folders,
+		Format:     format,
+		IDEVersion: proj_json.Get("MetaData.IDEVersion").Str,
+		Config:     config,
+		Summary:    summary,
+		listed:     listed,
 		Macros: make(map[string]parser.Macro),
-		Errors:    proj_errors,
+		Errors:     proj_errors,
 	}, nil
 }
 
-func (p *Project) Parse() {
-	for _, res := range p.Resources {
-		switch v := res.(type) {
-			case *ResGMScript:
-				macros := v.preprocess()
-				p.Macros = utils.MapMerge(p.Macros, macros)
-			case *ResGMObject:
-				macros := v.preprocess()
-				p.Macros = utils.MapMerge(p.Macros, macros)
+// LoadProjectBuffer loads a project like LoadProjectFS, except that the
+// file at target is read from code instead. This lets an editor check an
+// unsaved buffer against the rest of its project.
+//
+// target may be given as-is or relative to the project's directory.
+func LoadProjectBuffer(fsys FS, file_path string, target string, code string) (Project, error) {
+	target = path.Clean(target)
+	if _, err := fsys.Stat(target); err != nil {
+		in_root := path.Join(path.Dir(file_path), target)
+		if _, err := fsys.Stat(in_root); err == nil {
+			target = in_root
 		}
 	}
 
-	for _, res := range p.Resources {
-		switch v := res.(type) {
-			case *ResGMScript:
-				v.injectMacros(p.Macros)
-			case *ResGMObject:
-				v.injectMacros(p.Macros)
+	overlay := OverlayFS{
+		Base:  fsys,
+		Files: MemFS{target: []byte(code)},
+	}
+	return LoadProjectFS(overlay, file_path)
+}
+
+// FindScript returns the script loaded from file_path, which may be
+// given as-is or relative to the project's directory.
+func (p *Project) FindScript(file_path string) (*ResGMScript, error) {
+	if cr, ok := p.Owner(file_path).(codeResource); ok {
+		candidates := []string{path.Clean(file_path), path.Join(p.Root, file_path)}
+		for _, scr := range cr.Scripts() {
+			for _, c := range candidates {
+				if scr.GMLPath == c {
+					return scr, nil
+				}
+			}
 		}
 	}
+	return nil, fmt.Errorf("%v is not part of %v", file_path, p.File)
+}
 
+// codeResource is implemented by every resource that carries GML code
+// and therefore takes part in macro collection and parsing.
+type codeResource interface {
+	Scripts() []*ResGMScript
+}
+
+// Scripts returns every GML script in the project, in resource order.
+func (p *Project) Scripts() []*ResGMScript {
+	out := make([]*ResGMScript, 0, len(p.Resources))
 	for _, res := range p.Resources {
-		switch v := res.(type) {
-			case *ResGMScript:
-				v.parseAST()
-			case *ResGMObject:
-				v.parseAST()
+		if cr, ok := res.(codeResource); ok {
+			out = append(out, cr.Scripts()...)
+		}
+	}
+	return out
+}
+
+// Parse tokenizes every script, collects and injects macros and builds
+// the ASTs. Each phase is spread over p.Jobs workers; macros are merged
+// in script order, so the result doesn't depend on scheduling.
+//
+// When p.Cache is set, scripts whose contents and macro environment
+// haven't changed since they were last cached are not reparsed. Their
+// errors are restored from the cache and their Ast is left empty, until
+// ParseASTs is called.
+func (p *Project) Parse() {
+	jobs := max(p.Jobs, 1)
+	scripts := p.Scripts()
+
+	summarised := p.summarised()
+
+	macros := make([]map[string]parser.Macro, len(scripts))
+	utils.ParallelFor(len(scripts), jobs, func(i int) {
+		if sum, ok := summarised[scripts[i]]; ok {
+			scripts[i].useSummary(sum)
+			macros[i] = sum.Macros
+			return
+		}
+		macros[i] = p.Cache.preprocess(scripts[i])
+		scripts[i].Macros = macros[i]
+	})
+	p.Macros = utils.MapMerge(append([]map[string]parser.Macro{p.Macros}, macros...)...)
+
+	env := macroEnvHash(p.Macros)
+	utils.ParallelFor(len(scripts), jobs, func(i int) {
+		if _, ok := summarised[scripts[i]]; ok {
+			return
+		}
+		p.Cache.parse(scripts[i], env, p.Macros)
+	})
+}
+
+// ParseASTs builds the ASTs that Parse restored from the cache instead,
+// for passes that need every script's code. Library scripts standing in
+// for the summary are left as they are.
+func (p *Project) ParseASTs() {
+	scripts := make([]*ResGMScript, 0)
+	for _, scr := range p.Scripts() {
+		if scr.Cached {
+			scripts = append(scripts, scr)
 		}
 	}
+	utils.ParallelFor(len(scripts), max(p.Jobs, 1), func(i int) {
+		scripts[i].preprocess()
+		scripts[i].injectMacros(p.Macros)
+		scripts[i].parseAST()
+	})
 }
 
 func (p *Project) ErrorCount() int {
 	count := len(p.Errors)
 
 	for _, res := range p.Resources {
-		count += len(res.GetErrors())
+		if !p.IsLibrary(res) {
+			count += len(res.GetErrors())
+		}
 	}
 
 	return count
 }
 
+// AllErrors returns the errors of the project and its resources, except
+// for those in library code.
 func (p *Project) AllErrors() utils.Errors {
 	out := make(utils.Errors, len(p.Errors))
 	copy(out, p.Errors)
 	for _, res := range p.Resources {
-		out = append(out, res.GetErrors()...)
+		if !p.IsLibrary(res) {
+			out = append(out, res.GetErrors()...)
+		}
+	}
+	return out
+}
+
+// ExtensionFunctions returns every function declared by the project's
+// extensions, keyed by the name GML code calls it with.
+func (p *Project) ExtensionFunctions() map[string]ExtFunction {
+	out := make(map[string]ExtFunction)
+	for _, res := range p.Resources {
+		ext, ok := res.(*ResGMExtension)
+		if !ok {
+			continue
+		}
+		for _, fn := range ext.Functions {
+			out[fn.Name] = fn
+		}
+	}
+	return out
+}
+
+// ExtensionConstants returns every constant declared by the project's
+// extensions, keyed by name.
+func (p *Project) ExtensionConstants() map[string]ExtConstant {
+	out := make(map[string]ExtConstant)
+	for _, res := range p.Resources {
+		ext, ok := res.(*ResGMExtension)
+		if !ok {
+			continue
+		}
+		for _, c := range ext.Constants {
+			out[c.Name] = c
+		}
 	}
 	return out
 }
 
 type Resource interface {
 	GetPath() string
+	GetName() string
+	GetKind() string
+	GetParent() string
 	GetErrors() utils.Errors
 }
 
 type LoadResourceError struct{ error }
 type UnknownResourceError struct{ error }
 
-func loadResource(file_path string) (Resource, error) {
-	b, err := os.ReadFile(file_path)
+func loadResource(fsys FS, file_path string) (Resource, error) {
+	b, err := fsys.ReadFile(file_path)
 	if err != nil {
 		return nil, LoadResourceError{err}
 	}
 
 	res_json := gjson.ParseBytes(b)
-	rtype := res_json.Map()["resourceType"].Str
+	rtype := resourceKind(res_json)
+
+	base := BaseResource{
+		Path:   file_path,
+		Name:   resourceName(res_json),
+		Kind:   rtype,
+		Parent: res_json.Get("parent.path").Str,
+	}
 
 	switch rtype {
 	case "GMScript":
-		scr, err := loadGMScript(file_path)
+		scr, err := loadGMScript(fsys, file_path)
 		if err != nil {
 			return nil, err
 		}
+		scr.BaseResource = base
 		return &scr, nil
 
 	case "GMObject":
-		obj, err := loadGMObject(file_path, res_json)
+		obj, err := loadGMObject(fsys, base, res_json)
 		if err != nil {
 			return nil, err
 		}
 		return &obj, nil
 
-	case "GMShader", "GMRoom", "GMSprite":
-		return &BaseResource{file_path}, nil
+	case "GMTimeline":
+		tl, err := loadGMTimeline(fsys, base, res_json)
+		if err != nil {
+			return nil, err
+		}
+		return &tl, nil
+
+	case "GMExtension":
+		ext := loadGMExtension(base, res_json)
+		return &ext, nil
+
+	case "":
+		return nil, UnknownResourceError{fmt.Errorf("%v: Unknown resource type", file_path)}
 	}
 
-	return nil, UnknownResourceError{fmt.Errorf("Unknown resource type %v", rtype)}
+	// Sprites, sounds, rooms and everything else without code are only
+	// known by name and files.
+	return &base, nil
 }
 
 type BaseResource struct {
 	Path string
+	Name string
+	// Kind is GameMaker's resource type, e.g. "GMScript" or "GMObject".
+	Kind string
+	// Parent is the path of the folder the resource is in, as listed in
+	// the project's Folders, e.g. "folders/Scripts.yy".
+	Parent string
 }
 
 func (r *BaseResource) GetPath() string         { return r.Path }
+func (r *BaseResource) GetName() string         { return r.Name }
+func (r *BaseResource) GetKind() string         { return r.Kind }
+func (r *BaseResource) GetParent() string       { return r.Parent }
 func (r *BaseResource) GetErrors() utils.Errors { return nil }
 
 type ResGMScript struct {
 	BaseResource
 	GMLPath string
+	// Context describes where the script lives when that isn't obvious
+	// from GMLPath alone (e.g. which timeline moment it belongs to).
+	Context string
 	Script  string
 	Tokens  parser.Tokens
 	Ast     ast.ScriptNode
 	Errors  utils.Errors
+	// Macros holds the macros this script defines.
+	Macros map[string]parser.Macro
+	// Cached is set when Errors were restored from the parse cache, in
+	// which case Tokens and Ast are empty until ParseASTs is called.
+	Cached bool
+	// Summary is set when the script stands in for its entry in the
+	// library summary, in which case it has no Tokens or Ast.
+	Summary *ScriptSummary
 }
 
 func (r *ResGMScript) GetErrors() utils.Errors { return r.Errors }
+func (r *ResGMScript) Scripts() []*ResGMScript  { return []*ResGMScript{r} }
+
+// ErrorPrefix is what errors in the script start with.
+func (r *ResGMScript) ErrorPrefix() string {
+	if r.Context == "" {
+		return r.GMLPath
+	}
+	return r.GMLPath + " (" + r.Context + ")"
+}
 
 func (r *ResGMScript) preprocess() map[string]parser.Macro {
+	r.Ast = ast.ScriptNode{}
+	r.Errors = nil
+	r.Cached = false
+	r.Summary = nil
+
 	ts, err := parser.Pretokenize(r.Script)
 	if err != nil {
 		r.Tokens = nil
-		r.Errors = r.Errors.AddPrefix(r.GMLPath, err)
+		r.Errors = r.Errors.AddPrefix(r.ErrorPrefix(), err)
 		return nil
 	}
 	r.Tokens = ts
@@ -220,20 +459,20 @@ func (r *ResGMScript) parseAST() {
 	var err error
 	r.Ast, err = ast.ParseAST(r.Tokens)
 	if err != nil {
-		r.Errors = r.Errors.AddPrefix(r.GMLPath, err)
+		r.Errors = r.Errors.AddPrefix(r.ErrorPrefix(), err)
 	}
 }
 
-func loadGMScript(path string) (ResGMScript, error) {
+func loadGMScript(fsys FS, path string) (ResGMScript, error) {
 	gml_path := strings.TrimSuffix(path, ".yy") + ".gml"
 
-	b, err := os.ReadFile(gml_path)
+	b, err := fsys.ReadFile(gml_path)
 	if err != nil {
 		return ResGMScript{}, LoadResourceError{err}
 	}
 
 	return ResGMScript{
-		BaseResource: BaseResource{path},
+		BaseResource: BaseResource{Path: path},
 		GMLPath:      gml_path,
 		Script:       string(b),
 	}, nil
@@ -242,30 +481,20 @@ func loadGMScript(path string) (ResGMScript, error) {
 type ResGMObject struct {
 	BaseResource
 	Dir    string
-	Name   string
 	Events []ResGMEvent
-	Errors utils.Errors
+	// ParentObject is the name of the parent object, if there is one.
+	ParentObject string
+	// Properties are the names of the object's Variable Definitions.
+	Properties []string
+	Errors     utils.Errors
 }
 
-func (r *ResGMObject) preprocess() map[string]parser.Macro {
-	all_macros := make([]map[string]parser.Macro, 0)
-	for _, ev := range r.Events {
-		macros := ev.preprocess()
-		if macros != nil { all_macros = append(all_macros, macros) }
-	}
-	return utils.MapMerge(all_macros...)
-}
-
-func (r *ResGMObject) injectMacros(macros map[string]parser.Macro) {
-	for _, ev := range r.Events {
-		ev.injectMacros(macros)
-	}
-}
-
-func (r *ResGMObject) parseAST() {
-	for _, ev := range r.Events {
-		ev.parseAST()
+func (r *ResGMObject) Scripts() []*ResGMScript {
+	out := make([]*ResGMScript, len(r.Events))
+	for i := range r.Events {
+		out[i] = &r.Events[i].ResGMScript
 	}
+	return out
 }
 
 func (r *ResGMObject) GetErrors() utils.Errors {
@@ -277,18 +506,17 @@ func (r *ResGMObject) GetErrors() utils.Errors {
 	return out
 }
 
-func loadGMObject(file_path string, data gjson.Result) (ResGMObject, error) {
-	dir := path.Dir(file_path)
+func loadGMObject(fsys FS, base BaseResource, data gjson.Result) (ResGMObject, error) {
+	dir := path.Dir(base.Path)
 
 	data_map := data.Map()
-	name := data_map["name"].Str
 
 	ev_array := data_map["eventList"].Array()
 	events := make([]ResGMEvent, 0, len(ev_array))
 	errors := make(utils.Errors, 0)
 
 	for _, ev_json := range ev_array {
-		ev, err := loadGMEvent(dir, ev_json)
+		ev, err := loadGMEvent(fsys, base.Path, ev_json)
 		if err != nil {
 			errors = errors.Add(err)
 			continue
@@ -296,11 +524,17 @@ func loadGMObject(file_path string, data gjson.Result) (ResGMObject, error) {
 		events = append(events, ev)
 	}
 
+	props := make([]string, 0)
+	for _, prop := range data_map["properties"].Array() {
+		props = append(props, prop.Get("name").Str)
+	}
+
 	return ResGMObject{
-		BaseResource: BaseResource{file_path},
+		BaseResource: base,
 		Dir:          dir,
-		Name:         name,
 		Events:       events,
+		ParentObject: data_map["parentObjectId"].Get("name").Str,
+		Properties:   props,
 		Errors:       errors,
 	}, nil
 }
@@ -325,28 +559,73 @@ const (
 	GME_COUNT
 )
 
+// gmEventNumbers are the numbers GameMaker saves each event type as in
+// .yy files. 11 was the trigger event, which isn't supported.
+var gmEventNumbers = [GME_COUNT]int{
+	GME_Create:     0,
+	GME_Destroy:    1,
+	GME_Alarm:      2,
+	GME_Step:       3,
+	GME_Collision:  4,
+	GME_Keyboard:   5,
+	GME_Mouse:      6,
+	GME_Other:      7,
+	GME_Draw:       8,
+	GME_KeyPress:   9,
+	GME_KeyRelease: 10,
+	GME_CleanUp:    12,
+	GME_Gesture:    13,
+}
+
+// Number returns the number GameMaker saves the event type as.
+func (ev GM_EVENT) Number() int {
+	return gmEventNumbers[ev]
+}
+
+// eventTypeOf looks up an event type by the number GameMaker saves it as.
+func eventTypeOf(num int) (GM_EVENT, bool) {
+	for ev, n := range gmEventNumbers {
+		if n == num {
+			return GM_EVENT(ev), true
+		}
+	}
+	return GME_COUNT, false
+}
+
 type ResGMEvent struct {
 	ResGMScript
-	Type int
+	Type GM_EVENT
 	Num  int
+	// Collision is the name of the other object, for collision events.
+	Collision string
 }
 
-func getEventScriptPath(dir string, evtype GM_EVENT, evnum int) string {
-	if evtype >= GME_COUNT {
-		panic(fmt.Sprintf("Unhandled event script type %v (%v)", evtype, evnum))
+// getEventScriptPath returns the path of an event's script, without the
+// .gml extension. Since GameMaker 2.3, collision events are saved with
+// event number 0 and their scripts are named after the object they
+// collide with, e.g. Collision_obj_wall.gml, so that is what is used
+// whenever the other object is known.
+func getEventScriptPath(dir string, evtype GM_EVENT, evnum int, collision string) string {
+	if evtype == GME_Collision && collision != "" {
+		return path.Join(dir, evtype.String()+"_"+collision)
 	}
 	return path.Join(dir, evtype.String()+"_"+fmt.Sprint(evnum))
 }
 
-func loadGMEvent(dir string, data gjson.Result) (ResGMEvent, error) {
+// loadGMEvent loads an event listed in the object's .yy file at yy_path.
+func loadGMEvent(fsys FS, yy_path string, data gjson.Result) (ResGMEvent, error) {
 	data_map := data.Map()
 
 	evnum := int(data_map["eventNum"].Num)
-	evtype := int(data_map["eventType"].Num)
+	evtype, ok := eventTypeOf(int(data_map["eventType"].Num))
+	if !ok {
+		return ResGMEvent{}, fmt.Errorf("%v: unknown event type %v (%v)", yy_path, data_map["eventType"].Raw, evnum)
+	}
+	collision := data_map["collisionObjectId"].Get("name").Str
 
-	path := getEventScriptPath(dir, GM_EVENT(evtype), evnum)
+	path := getEventScriptPath(path.Dir(yy_path), evtype, evnum, collision)
 
-	script, err := loadGMScript(path)
+	script, err := loadGMScript(fsys, path)
 	if err != nil {
 		return ResGMEvent{}, err
 	}
@@ -355,5 +634,82 @@ func loadGMEvent(dir string, data gjson.Result) (ResGMEvent, error) {
 		ResGMScript: script,
 		Type:        evtype,
 		Num:         evnum,
+		Collision:   collision,
+	}, nil
+}
+
+type ResGMTimeline struct {
+	BaseResource
+	Dir     string
+	Moments []ResGMMoment
+	Errors  utils.Errors
+}
+
+type ResGMMoment struct {
+	ResGMScript
+	Timeline string
+	Step     int
+}
+
+func (r *ResGMTimeline) Scripts() []*ResGMScript {
+	out := make([]*ResGMScript, len(r.Moments))
+	for i := range r.Moments {
+		out[i] = &r.Moments[i].ResGMScript
+	}
+	return out
+}
+
+func (r *ResGMTimeline) GetErrors() utils.Errors {
+	out := make(utils.Errors, len(r.Errors))
+	copy(out, r.Errors)
+	for _, m := range r.Moments {
+		out = append(out, m.Errors...)
+	}
+	return out
+}
+
+func loadGMTimeline(fsys FS, base BaseResource, data gjson.Result) (ResGMTimeline, error) {
+	dir := path.Dir(base.Path)
+
+	data_map := data.Map()
+
+	moment_array := data_map["momentList"].Array()
+	moments := make([]ResGMMoment, 0, len(moment_array))
+	errors := make(utils.Errors, 0)
+
+	for _, moment_json := range moment_array {
+		moment, err := loadGMMoment(fsys, dir, base.Name, moment_json)
+		if err != nil {
+			errors = errors.Add(err)
+			continue
+		}
+		moments = append(moments, moment)
+	}
+
+	return ResGMTimeline{
+		BaseResource: base,
+		Dir:          dir,
+		Moments:      moments,
+		Errors:       errors,
+	}, nil
+}
+
+func getMomentScriptPath(dir string, step int) string {
+	return path.Join(dir, "moment_"+fmt.Sprint(step))
+}
+
+func loadGMMoment(fsys FS, dir string, timeline string, data gjson.Result) (ResGMMoment, error) {
+	step := int(data.Get("moment").Num)
+
+	script, err := loadGMScript(fsys, getMomentScriptPath(dir, step))
+	if err != nil {
+		return ResGMMoment{}, err
+	}
+	script.Context = fmt.Sprintf("timeline %v, moment %v", timeline, step)
+
+	return ResGMMoment{
+		ResGMScript: script,
+		Timeline:    timeline,
+		Step:        step,
 	}, nil
 }
@@ -0,0 +1,60 @@
+package project
+
+import (
+	"fmt"
+	"gmtc/ast"
+	"slices"
+	"testing"
+)
+
+// parsedScripts renders the ASTs and errors of a project's scripts.
+func parsedScripts(p *Project) []string {
+	out := make([]string, 0)
+	for _, scr := range p.Scripts() {
+		out = append(out, scr.GMLPath+"\n"+ast.NodeString(&scr.Ast)+fmt.Sprint(scr.Errors))
+	}
+	return out
+}
+
+func TestParseJobs(t *testing.T) {
+	scripts := make([]string, 0)
+	for i := 0; i < 40; i++ {
+		code := fmt.Sprintf("#macro M%v %v\nvar a = M%v + SHARED;\n", i, i, (i+1)%40)
+		if i%7 == 0 {
+			code += "var b = ;\n"
+		}
+		if i%10 == 0 {
+			code += fmt.Sprintf("#macro SHARED %v\n", i)
+		}
+		scripts = append(scripts, fmt.Sprintf("scr_%02d", i), code)
+	}
+	fsys := memProject(scripts...)
+
+	serial := loadMemProject(t, fsys)
+	serial.Jobs = 1
+	serial.Parse()
+
+	for run := 0; run < 5; run++ {
+		parallel := loadMemProject(t, fsys)
+		parallel.Jobs = 8
+		parallel.Parse()
+
+		if got, want := parsedScripts(&parallel), parsedScripts(&serial); !slices.Equal(got, want) {
+			t.Fatalf("parsing with 8 jobs differs from parsing with 1:\n%v\nwant:\n%v", got, want)
+		}
+		if len(parallel.Macros) != len(serial.Macros) {
+			t.Fatalf("got %v macros, want %v", len(parallel.Macros), len(serial.Macros))
+		}
+		for name, m := range serial.Macros {
+			if !sameMacro(parallel.Macros[name], m) {
+				t.Errorf("macro %v differs", name)
+			}
+		}
+	}
+
+	// Macros defined more than once take the definition of the last
+	// script, whatever order the workers finish in.
+	if v := serial.Macros["SHARED"].Value; len(v) != 1 || v[0].Value != "30" {
+		t.Errorf("SHARED is %v, want 30", v)
+	}
+}
@@ -0,0 +1,425 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Creating resources means writing .yy files GameMaker will accept and
+// registering them in the .yyp. New files follow the format and layout
+// of the project file itself, so a 2.3-era project gets 2.3-era files
+// and a newer one gets files with "$GM..." headers and sorted keys.
+
+type ResourceExistsError struct{ error }
+
+// yyStyle captures which of GameMaker's two .yy layouts, and which line
+// endings, new files should be written with.
+type yyStyle struct {
+	sorted       bool
+	newline      string
+	finalNewline bool
+}
+
+func styleOf(yyp *YYDocument) yyStyle {
+	return yyStyle{
+		sorted:       yyp.Root.Has("%Name"),
+		newline:      yyp.Newline,
+		finalNewline: yyp.FinalNewline,
+	}
+}
+
+// yyHeaders holds the values GameMaker writes in the "$GM..." header
+// key of each kind of resource, in the newer format.
+var yyHeaders = map[string]string{
+	"GMScript":         "v1",
+	"GMEvent":          "v1",
+	"GMObject":         "",
+	"GMFolder":         "",
+	"GMObjectProperty": "v1",
+}
+
+// resourceValue builds a .yy object of the given kind. fields are given
+// in the order the 2.3 format uses; the bookkeeping fields GameMaker
+// adds to every resource are filled in according to the style.
+func (st yyStyle) resourceValue(kind string, name string, inline bool, fields ...YYField) *YYValue {
+	v := YYObject(inline)
+
+	if st.sorted {
+		v.Fields = append(v.Fields,
+			YYField{"$" + kind, YYString(yyHeaders[kind])},
+			YYField{"%Name", YYString(name)},
+		)
+		v.Fields = append(v.Fields, fields...)
+		v.Fields = append(v.Fields,
+			YYField{"name", YYString(name)},
+			YYField{"resourceType", YYString(kind)},
+			YYField{"resourceVersion", YYString("2.0")},
+		)
+		slices.SortStableFunc(v.Fields, func(a, b YYField) int {
+			return strings.Compare(strings.ToLower(a.Key), strings.ToLower(b.Key))
+		})
+		return v
+	}
+
+	v.Fields = append(v.Fields, fields...)
+	v.Fields = append(v.Fields,
+		YYField{"resourceVersion", YYString("1.0")},
+		YYField{"name", YYString(name)},
+		YYField{"tags", YYArray(true)},
+		YYField{"resourceType", YYString(kind)},
+	)
+	return v
+}
+
+func (st yyStyle) document(root *YYValue) *YYDocument {
+	return &YYDocument{
+		Root:         root,
+		Newline:      st.newline,
+		Indent:       "  ",
+		Spaced:       !st.sorted,
+		Trailing:     true,
+		FinalNewline: st.finalNewline,
+	}
+}
+
+// parentRef builds the "parent" reference of a resource in folder_path,
+// or of one at the top of the project if folder_path is empty.
+func (p *Project) parentRef(yyp *YYDocument, folder_path string) *YYValue {
+	var ref *YYValue
+	if folder_path == "" {
+		ref = YYRef(yyp.Root.Get("name").Str(), path.Base(p.File))
+	} else {
+		ref = YYRef(strings.TrimSuffix(path.Base(folder_path), ".yy"), folder_path)
+	}
+	ref.Inline = styleOf(yyp).sorted
+	return ref
+}
+
+// relPath returns file_path relative to the project's directory, the way
+// the .yy files refer to other resources.
+func (p *Project) relPath(file_path string) string {
+	if p.Root == "." {
+		return file_path
+	}
+	rel, _ := strings.CutPrefix(file_path, p.Root+"/")
+	return rel
+}
+
+func (p *Project) writeFS() (WriteFS, error) {
+	wfs, ok := p.FS.(WriteFS)
+	if !ok {
+		return nil, errors.New("the project's file system is read-only")
+	}
+	return wfs, nil
+}
+
+// itemName returns the name an entry of the .yyp's resources or Folders
+// lists is sorted by.
+func itemName(v *YYValue) string {
+	if id := v.Get("id"); id != nil {
+		return id.Get("name").Str()
+	}
+	return v.Get("folderPath").Str()
+}
+
+func itemLess(a, b *YYValue) bool {
+	return strings.ToLower(itemName(a)) < strings.ToLower(itemName(b))
+}
+
+// unfoldEmpty makes an empty list put its items on their own lines once
+// it has some. An empty list reads back as "[]", which looks inline, but
+// GameMaker only writes the items inside resources inline.
+func unfoldEmpty(list *YYValue) {
+	if len(list.Items) == 0 {
+		list.Inline = false
+	}
+}
+
+// insertItem adds item to a list of the .yyp, keeping the list sorted if
+// it already was.
+func insertItem(list *YYValue, item *YYValue) {
+	unfoldEmpty(list)
+	sorted := slices.IsSortedFunc(list.Items, func(a, b *YYValue) int {
+		if itemLess(a, b) {
+			return -1
+		}
+		if itemLess(b, a) {
+			return 1
+		}
+		return 0
+	})
+	if sorted {
+		for i, it := range list.Items {
+			if itemLess(item, it) {
+				list.Items = slices.Insert(list.Items, i, item)
+				return
+			}
+		}
+	}
+	list.Items = append(list.Items, item)
+}
+
+// ensureFolder adds folder_path, and any of its missing parents, to the
+// .yyp's folder tree.
+func (p *Project) ensureFolder(yyp *YYDocument, folder_path string) {
+	if folder_path == "" {
+		return
+	}
+
+	folders := yyp.Root.Get("Folders")
+	if folders == nil {
+		folders = YYArray(false)
+		yyp.Root.Set("Folders", folders)
+	}
+	for _, f := range folders.Items {
+		if f.Get("folderPath").Str() == folder_path {
+			return
+		}
+	}
+
+	f := Folder{Path: folder_path}
+	p.ensureFolder(yyp, f.ParentPath())
+
+	st := styleOf(yyp)
+	name := strings.TrimSuffix(path.Base(folder_path), ".yy")
+	fields := []YYField{{"folderPath", YYString(folder_path)}}
+	if !st.sorted {
+		fields = append(fields, YYField{"order", YYInt(len(folders.Items))})
+	}
+	insertItem(folders, st.resourceValue("GMFolder", name, true, fields...))
+}
+
+// register lists a new resource in the .yyp and writes it.
+func (p *Project) register(wfs WriteFS, yyp *YYDocument, name string, res_path string, folder_path string) error {
+	err := p.addEntry(yyp, name, res_path, folder_path)
+	if err != nil {
+		return err
+	}
+	err = WriteYY(wfs, p.File, yyp)
+	if err != nil {
+		return err
+	}
+	return p.Reload()
+}
+
+// addEntry lists a new resource, and the folder it is in, in the .yyp.
+func (p *Project) addEntry(yyp *YYDocument, name string, res_path string, folder_path string) error {
+	p.ensureFolder(yyp, folder_path)
+
+	resources := yyp.Root.Get("resources")
+	if resources == nil {
+		return fmt.Errorf("%v has no resources list", p.File)
+	}
+
+	entry := YYObject(true, YYField{"id", YYRef(name, res_path)})
+	if !styleOf(yyp).sorted {
+		entry.Set("order", YYInt(len(resources.Items)))
+	}
+	insertItem(resources, entry)
+	return nil
+}
+
+func (p *Project) checkNewName(name string) error {
+	if name == "" {
+		return errors.New("a resource needs a name")
+	}
+	for i := 0; i < len(name); i++ {
+		if !isIdentByte(name[i], i) {
+			return fmt.Errorf("%v is not a valid resource name", name)
+		}
+	}
+	if res := p.Resource(name); res != nil {
+		return ResourceExistsError{fmt.Errorf("%v already exists (%v)", name, res.GetPath())}
+	}
+	return nil
+}
+
+func isIdentByte(c byte, i int) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+}
+
+// NewScript, NewObject and AddEvent write straight to the project's
+// file system and reload the project afterwards.
+
+// NewScript creates a script resource containing an empty function of
+// the same name, in the folder at folder_path ("" for the top of the
+// project).
+func (p *Project) NewScript(name string, folder_path string) error {
+	err := p.checkNewName(name)
+	if err != nil {
+		return err
+	}
+	wfs, err := p.writeFS()
+	if err != nil {
+		return err
+	}
+	yyp, err := ReadYY(p.FS, p.File)
+	if err != nil {
+		return err
+	}
+
+	st := styleOf(yyp)
+	res_path := path.Join("scripts", name, name+".yy")
+	full_path := path.Join(p.Root, res_path)
+
+	doc := st.document(st.resourceValue("GMScript", name, false,
+		YYField{"isDnD", YYBool(false)},
+		YYField{"isCompatibility", YYBool(false)},
+		YYField{"parent", p.parentRef(yyp, folder_path)},
+	))
+
+	code := "function " + name + "() {" + st.newline + st.newline + "}" + st.newline
+	err = wfs.WriteFile(strings.TrimSuffix(full_path, ".yy")+".gml", []byte(code))
+	if err != nil {
+		return err
+	}
+	err = WriteYY(wfs, full_path, doc)
+	if err != nil {
+		return err
+	}
+
+	return p.register(wfs, yyp, name, res_path, folder_path)
+}
+
+// NewObject creates an object resource without any events, in the
+// folder at folder_path ("" for the top of the project).
+func (p *Project) NewObject(name string, folder_path string) error {
+	err := p.checkNewName(name)
+	if err != nil {
+		return err
+	}
+	wfs, err := p.writeFS()
+	if err != nil {
+		return err
+	}
+	yyp, err := ReadYY(p.FS, p.File)
+	if err != nil {
+		return err
+	}
+
+	st := styleOf(yyp)
+	res_path := path.Join("objects", name, name+".yy")
+
+	fields := []YYField{
+		{"spriteId", YYNull()},
+		{"solid", YYBool(false)},
+		{"visible", YYBool(true)},
+	}
+	if st.sorted {
+		fields = append(fields, YYField{"managed", YYBool(true)})
+	}
+	fields = append(fields,
+		YYField{"spriteMaskId", YYNull()},
+		YYField{"persistent", YYBool(false)},
+		YYField{"parentObjectId", YYNull()},
+		YYField{"physicsObject", YYBool(false)},
+		YYField{"physicsSensor", YYBool(false)},
+		YYField{"physicsShape", YYInt(1)},
+		YYField{"physicsGroup", YYInt(1)},
+		YYField{"physicsDensity", &YYValue{Kind: YY_RAW, Raw: "0.5"}},
+		YYField{"physicsRestitution", &YYValue{Kind: YY_RAW, Raw: "0.1"}},
+		YYField{"physicsLinearDamping", &YYValue{Kind: YY_RAW, Raw: "0.1"}},
+		YYField{"physicsAngularDamping", &YYValue{Kind: YY_RAW, Raw: "0.1"}},
+		YYField{"physicsFriction", &YYValue{Kind: YY_RAW, Raw: "0.2"}},
+		YYField{"physicsStartAwake", YYBool(true)},
+		YYField{"physicsKinematic", YYBool(false)},
+		YYField{"physicsShapePoints", YYArray(false)},
+		YYField{"eventList", YYArray(false)},
+		YYField{"properties", YYArray(false)},
+		YYField{"overriddenProperties", YYArray(false)},
+		YYField{"parent", p.parentRef(yyp, folder_path)},
+	)
+
+	doc := st.document(st.resourceValue("GMObject", name, false, fields...))
+	err = WriteYY(wfs, path.Join(p.Root, res_path), doc)
+	if err != nil {
+		return err
+	}
+
+	return p.register(wfs, yyp, name, res_path, folder_path)
+}
+
+// ParseEventType looks up an event type by the name its scripts are
+// saved under, e.g. "Step" or "KeyPress".
+func ParseEventType(name string) (GM_EVENT, error) {
+	for ev := GM_EVENT(0); ev < GME_COUNT; ev++ {
+		if strings.EqualFold(ev.String(), name) {
+			return ev, nil
+		}
+	}
+	return GME_COUNT, fmt.Errorf("unknown event type %v", name)
+}
+
+// AddEvent adds an event, with an empty script, to an existing object.
+// collision names the other object of a collision event and is ignored
+// for every other event type.
+func (p *Project) AddEvent(object string, evtype GM_EVENT, evnum int, collision string) error {
+	obj, ok := p.ResourceOfKind("GMObject", object).(*ResGMObject)
+	if !ok {
+		return fmt.Errorf("no object called %v", object)
+	}
+	if evtype < 0 || evtype >= GME_COUNT {
+		return fmt.Errorf("unknown event type %v", evtype)
+	}
+
+	var collision_ref *YYValue = YYNull()
+	if evtype == GME_Collision {
+		other := p.ResourceOfKind("GMObject", collision)
+		if other == nil {
+			return fmt.Errorf("collision events need another object, %q isn't one", collision)
+		}
+		collision_ref = YYRef(collision, p.relPath(other.GetPath()))
+		evnum = 0
+	} else {
+		collision = ""
+	}
+
+	for _, ev := range obj.Events {
+		if ev.Type == evtype && ev.Num == evnum && ev.Collision == collision {
+			return fmt.Errorf("%v already has a %v %v event", object, evtype, evnum)
+		}
+	}
+
+	wfs, err := p.writeFS()
+	if err != nil {
+		return err
+	}
+	yyp, err := ReadYY(p.FS, p.File)
+	if err != nil {
+		return err
+	}
+	doc, err := ReadYY(p.FS, obj.Path)
+	if err != nil {
+		return err
+	}
+
+	events := doc.Root.Get("eventList")
+	if events == nil {
+		events = YYArray(false)
+		doc.Root.Set("eventList", events)
+	}
+	unfoldEmpty(events)
+
+	st := styleOf(yyp)
+	st.sorted = doc.Root.Has("%Name")
+	events.Items = append(events.Items, st.resourceValue("GMEvent", "", true,
+		YYField{"isDnD", YYBool(false)},
+		YYField{"eventNum", YYInt(evnum)},
+		YYField{"eventType", YYInt(evtype.Number())},
+		YYField{"collisionObjectId", collision_ref},
+	))
+
+	script_path := getEventScriptPath(obj.Dir, evtype, evnum, collision) + ".gml"
+	err = wfs.WriteFile(script_path, []byte{})
+	if err != nil {
+		return err
+	}
+	err = WriteYY(wfs, obj.Path, doc)
+	if err != nil {
+		return err
+	}
+	return p.Reload()
+}
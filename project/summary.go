@@ -0,0 +1,206 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gmtc/ast"
+	"gmtc/parser"
+	"io/fs"
+	"path"
+)
+
+// Summary records what library scripts contribute to the rest of the
+// project, so that they don't need to be parsed on every run. Unlike the
+// parse cache it lives in the project, and can be committed alongside
+// the libraries it describes.
+type Summary struct {
+	Version string `json:"version"`
+	// Scripts are keyed by the path of their .gml, relative to the
+	// project's directory.
+	Scripts map[string]ScriptSummary `json:"scripts"`
+}
+
+type ScriptSummary struct {
+	// Hash is the hash of the script's contents when it was summarised.
+	Hash      string                  `json:"hash"`
+	Macros    map[string]parser.Macro `json:"macros"`
+	Functions []string                `json:"functions"`
+	// Enums maps the enums the script declares to their members.
+	Enums map[string][]string `json:"enums"`
+	// Globalvars are the variables the script declares with globalvar, and
+	// Globals those it assigns through global. Both are mapped to their
+	// type, or "" if it isn't known.
+	Globalvars map[string]string `json:"globalvars"`
+	Globals    map[string]string `json:"globals"`
+}
+
+// LoadSummary reads the summary at file_path. A summary that is missing
+// or was written by another version of gmtc is ignored.
+func LoadSummary(fsys FS, file_path string) (*Summary, error) {
+	b, err := fsys.ReadFile(file_path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sum Summary
+	err = json.Unmarshal(b, &sum)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", file_path, err)
+	}
+	if sum.Version != Version {
+		return nil, nil
+	}
+	return &sum, nil
+}
+
+// SummaryPath returns where the project's summary is kept, or "" if the
+// config doesn't ask for one.
+func (p *Project) SummaryPath() string {
+	if p.Config.Library.Summary == "" {
+		return ""
+	}
+	return path.Join(p.Root, p.Config.Library.Summary)
+}
+
+// declaredFunctions returns the names of the functions declared at the
+// top of a script, which are global. Methods of constructors and
+// functions declared inside other functions aren't.
+func declaredFunctions(root *ast.ScriptNode) []string {
+	out := make([]string, 0)
+	for _, stmt := range root.Children {
+		if fn, ok := stmt.(*ast.FuncDecl); ok && fn.Name != nil {
+			out = append(out, fn.Name.Value)
+		}
+	}
+	return out
+}
+
+// functions returns the global functions a script declares. Scripts
+// standing in for the summary take them from it, and scripts that haven't
+// been parsed, such as cache hits, are parsed for it.
+func (r *ResGMScript) functions() []string {
+	if r.Summary != nil {
+		return r.Summary.Functions
+	}
+	if len(r.Ast.Children) > 0 {
+		return declaredFunctions(&r.Ast)
+	}
+	ts, err := parser.Pretokenize(r.Script)
+	if err != nil {
+		return nil
+	}
+	macros := ts.ExtractMacros()
+	root, _ := ast.ParseAST(ts.InsertMacros(macros).Clean(macros))
+	return declaredFunctions(&root)
+}
+
+// declaredGlobals fills in the enums and global variables that a
+// script's code declares or assigns, leaving their types unknown.
+func (sum *ScriptSummary) declaredGlobals(root *ast.ScriptNode) {
+	sum.Enums = make(map[string][]string)
+	sum.Globalvars = make(map[string]string)
+	sum.Globals = make(map[string]string)
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Enum:
+			members := make([]string, len(n.Members))
+			for i, m := range n.Members {
+				members[i] = m.Name.Value
+			}
+			sum.Enums[n.Name.Value] = members
+		case *ast.VarDecl:
+			if n.Keyword() == "globalvar" {
+				sum.Globalvars[n.Name.Value] = ""
+			}
+		case *ast.Assign:
+			attr, ok := n.Left.(*ast.Attr)
+			if !ok || n.Op.Type != parser.T_ASSIGN {
+				break
+			}
+			if v, ok := attr.Value.(*ast.Simple); ok && v.Type == ast.AST_IDENT && v.Value.Value == "global" {
+				sum.Globals[attr.Name.Value] = ""
+			}
+		}
+		return true
+	})
+}
+
+// libraryScripts returns the scripts of every library resource.
+func (p *Project) libraryScripts() []*ResGMScript {
+	out := make([]*ResGMScript, 0)
+	for _, res := range p.Resources {
+		if cr, ok := res.(codeResource); ok && p.IsLibrary(res) {
+			out = append(out, cr.Scripts()...)
+		}
+	}
+	return out
+}
+
+// Summarise builds a summary of the project's library scripts, without
+// the types of their global variables. The project must have been
+// parsed.
+func (p *Project) Summarise() Summary {
+	p.ParseASTs()
+	sum := Summary{
+		Version: Version,
+		Scripts: make(map[string]ScriptSummary),
+	}
+	for _, scr := range p.libraryScripts() {
+		scr_sum := ScriptSummary{
+			Hash:      hashParts(scr.Script),
+			Macros:    scr.Macros,
+			Functions: declaredFunctions(&scr.Ast),
+		}
+		scr_sum.declaredGlobals(&scr.Ast)
+		sum.Scripts[p.relPath(scr.GMLPath)] = scr_sum
+	}
+	return sum
+}
+
+// WriteSummary writes a summary of the project to its summary path.
+func (p *Project) WriteSummary(sum Summary) error {
+	file_path := p.SummaryPath()
+	if file_path == "" {
+		return fmt.Errorf("%v doesn't set library.summary", ConfigFile)
+	}
+	wfs, err := p.writeFS()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(sum, "", "\t")
+	if err != nil {
+		return err
+	}
+	return wfs.WriteFile(file_path, b)
+}
+
+// summarised returns the library scripts the summary is up to date for,
+// with their summaries.
+func (p *Project) summarised() map[*ResGMScript]ScriptSummary {
+	out := make(map[*ResGMScript]ScriptSummary)
+	if p.Summary == nil {
+		return out
+	}
+	for _, scr := range p.libraryScripts() {
+		sum, ok := p.Summary.Scripts[p.relPath(scr.GMLPath)]
+		if ok && sum.Hash == hashParts(scr.Script) {
+			out[scr] = sum
+		}
+	}
+	return out
+}
+
+// useSummary stands in for preprocessing and parsing r.
+func (r *ResGMScript) useSummary(sum ScriptSummary) {
+	r.Ast = ast.ScriptNode{}
+	r.Tokens = nil
+	r.Errors = nil
+	r.Cached = false
+	r.Summary = &sum
+	r.Macros = sum.Macros
+}
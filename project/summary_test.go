@@ -0,0 +1,35 @@
+package project
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSummariseFunctions(t *testing.T) {
+	fsys := memProject("lib_a", `
+function lib_a(_x) {
+	function helper() {}
+	return _x;
+}
+function Vec(_x) constructor {
+	x = _x;
+	function add(_v) { return new Vec(x + _v.x); }
+	static len = function() { return abs(x); };
+}
+`)
+	fsys[ConfigFile] = []byte(`{"library":{"patterns":["lib_*"]}}`)
+	p := loadMemProject(t, fsys)
+	p.Parse()
+
+	sum := p.Summarise()
+	got := sum.Scripts["scripts/lib_a/lib_a.gml"].Functions
+	if !slices.Equal(got, []string{"lib_a", "Vec"}) {
+		t.Errorf("got functions %v, want only those declared at the top", got)
+	}
+
+	// Scripts without an AST, like cache hits, give the same.
+	p = loadMemProject(t, fsys)
+	if got := p.Scripts()[0].functions(); !slices.Equal(got, []string{"lib_a", "Vec"}) {
+		t.Errorf("unparsed script declares %v", got)
+	}
+}
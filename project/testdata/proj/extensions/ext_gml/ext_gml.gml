@@ -0,0 +1,2 @@
+#define ext_gml_twice
+return argument0 * 2;
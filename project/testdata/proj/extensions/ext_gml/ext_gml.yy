@@ -0,0 +1,16 @@
+{
+  "optionsFile": "options.json",
+  "options": [],
+  "exportToGame": true,
+  "supportedTargets": -1,
+  "extensionVersion": "1.0.0",
+  "files": [
+    {"filename":"ext_gml.gml","origname":"","init":"","final":"","kind":2,"uncompress":false,"functions":[
+        {"externalName":"ext_gml_twice","kind":2,"help":"ext_gml_twice(n)","hidden":false,"returnType":2,"argCount":1,"args":[2,],"resourceVersion":"1.0","name":"ext_gml_twice","tags":[],"resourceType":"GMExtensionFunction",},
+      ],"constants":[],"ProxyFiles":[],"copyToTargets":-1,"order":[],"resourceVersion":"1.0","name":"","tags":[],"resourceType":"GMExtensionFile",},
+  ],
+  "resourceVersion": "1.2",
+  "name": "ext_gml",
+  "tags": [],
+  "resourceType": "GMExtension",
+}
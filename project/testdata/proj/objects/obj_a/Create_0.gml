@@ -0,0 +1 @@
+value = scr_a();
@@ -0,0 +1,15 @@
+{
+  "spriteId": null,
+  "solid": false,
+  "visible": true,
+  "parentObjectId": null,
+  "persistent": false,
+  "eventList": [
+    {"isDnD":false,"eventNum":0,"eventType":0,"collisionObjectId":null,"resourceVersion":"1.0","name":"","tags":[],"resourceType":"GMEvent",},
+  ],
+  "properties": [],
+  "resourceVersion": "1.0",
+  "name": "obj_a",
+  "tags": [],
+  "resourceType": "GMObject",
+}
@@ -0,0 +1 @@
+global.level = 1;
@@ -0,0 +1,27 @@
+{
+  "isDnd": false,
+  "volume": 1.0,
+  "parentRoom": null,
+  "views": [],
+  "layers": [
+    {"instances":[
+        {"properties":[],"isDnd":false,"objectId":{"name":"obj_a","path":"objects/obj_a/obj_a.yy",},"inheritCode":false,"hasCreationCode":true,"colour":4294967295,"rotation":0.0,"scaleX":1.0,"scaleY":1.0,"imageIndex":0,"imageSpeed":1.0,"inheritedItemId":null,"frozen":false,"ignore":false,"inheritItemSettings":false,"x":32.0,"y":32.0,"resourceVersion":"1.0","name":"inst_1A2B3C4D","tags":[],"resourceType":"GMRInstance",},
+      ],"visible":true,"depth":0,"userdefinedDepth":false,"inheritLayerDepth":false,"inheritLayerSettings":false,"gridX":32,"gridY":32,"layers":[],"hierarchyFrozen":false,"resourceVersion":"1.0","name":"Instances","tags":[],"resourceType":"GMRInstanceLayer",},
+  ],
+  "inheritLayers": false,
+  "creationCodeFile": "rooms/rm_a/RoomCreationCode.gml",
+  "inheritCode": false,
+  "instanceCreationOrder": [
+    {"name":"inst_1A2B3C4D","path":"rooms/rm_a/rm_a.yy",},
+  ],
+  "inheritCreationOrder": false,
+  "sequenceId": null,
+  "roomSettings": {"inheritRoomSettings":false,"Width":640,"Height":480,"persistent":false,},
+  "viewSettings": {"inheritViewSettings":false,"enableViews":false,"clearViewBackground":false,"clearDisplayBuffer":true,},
+  "physicsSettings": {"inheritPhysicsSettings":false,"PhysicsWorld":false,"PhysicsWorldGravityX":0.0,"PhysicsWorldGravityY":10.0,"PhysicsWorldPixToMetres":0.1,},
+  "parent": null,
+  "resourceVersion": "1.0",
+  "name": "rm_a",
+  "tags": [],
+  "resourceType": "GMRoom",
+}
@@ -0,0 +1,3 @@
+function scr_a() {
+	return ext_gml_twice(2);
+}
@@ -0,0 +1,8 @@
+{
+  "isDnD": false,
+  "isCompatibility": false,
+  "resourceVersion": "1.0",
+  "name": "scr_a",
+  "tags": [],
+  "resourceType": "GMScript",
+}
@@ -0,0 +1,23 @@
+{
+  "optionsFile": "options.json",
+  "options": [],
+  "exportToGame": true,
+  "supportedTargets": -1,
+  "extensionVersion": "1.0.0",
+  "files": [
+    {"filename":"ext_a.js","origname":"","init":"","final":"","kind":5,"uncompress":false,"functions":[
+        {"externalName":"jsAdd","kind":5,"help":"ext_add(a, b)","hidden":false,"returnType":2,"argCount":2,"args":[2,2,],"resourceVersion":"1.0","name":"ext_add","tags":[],"resourceType":"GMExtensionFunction",},
+        {"externalName":"jsLog","kind":5,"help":"ext_log(...)","hidden":false,"returnType":2,"argCount":-1,"args":[],"resourceVersion":"1.0","name":"ext_log","tags":[],"resourceType":"GMExtensionFunction",},
+      ],"constants":[
+        {"value":"12","hidden":false,"resourceVersion":"1.0","name":"EXT_TWELVE","tags":[],"resourceType":"GMExtensionConstant",},
+      ],"ProxyFiles":[],"copyToTargets":-1,"order":[],"resourceVersion":"1.0","name":"","tags":[],"resourceType":"GMExtensionFile",},
+  ],
+  "parent": {
+    "name": "Extensions",
+    "path": "folders/Extensions.yy",
+  },
+  "resourceVersion": "1.2",
+  "name": "ext_a",
+  "tags": [],
+  "resourceType": "GMExtension",
+}
@@ -0,0 +1,9 @@
+{
+  "name": "plain",
+  "tags": [
+    "a",
+    "b"
+  ],
+  "inline": {"x": 1, "y": 2},
+  "last": true
+}
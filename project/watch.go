@@ -0,0 +1,265 @@
+package project
+
+import (
+	"gmtc/parser"
+	"gmtc/utils"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Watcher polls the files of a project and incrementally updates it
+// whenever any of them change.
+type Watcher struct {
+	Project *Project
+	files   map[string]time.Time
+}
+
+func NewWatcher(p *Project) *Watcher {
+	return &Watcher{
+		Project: p,
+		files:   p.snapshot(),
+	}
+}
+
+func isWatchedFile(file_path string) bool {
+	ext := path.Ext(file_path)
+	return ext == ".gml" || ext == ".yy" || ext == ".yyp"
+}
+
+// snapshot records the modification time of every file the project
+// could depend on.
+func (p *Project) snapshot() map[string]time.Time {
+	out := make(map[string]time.Time)
+
+	if p.Kind != PK_PROJECT {
+		if info, err := p.FS.Stat(p.File); err == nil {
+			out[p.File] = info.ModTime()
+		}
+		return out
+	}
+
+	fs.WalkDir(p.FS, p.Root, func(file_path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isWatchedFile(file_path) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			out[file_path] = info.ModTime()
+		}
+		return nil
+	})
+
+	return out
+}
+
+// Poll checks for added, modified and removed files and, if there are
+// any, updates the project accordingly. It returns the changed paths.
+func (w *Watcher) Poll() ([]string, error) {
+	files := w.Project.snapshot()
+	changed := make([]string, 0)
+
+	for file_path, mtime := range files {
+		old, ok := w.files[file_path]
+		if !ok || !old.Equal(mtime) {
+			changed = append(changed, file_path)
+		}
+	}
+	for file_path := range w.files {
+		if _, ok := files[file_path]; !ok {
+			changed = append(changed, file_path)
+		}
+	}
+
+	w.files = files
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	slices.Sort(changed)
+	return changed, w.Project.Update(changed)
+}
+
+// Watch calls Poll every interval and passes each batch of changes to
+// fn. It only returns if updating the project fails.
+func (w *Watcher) Watch(interval time.Duration, fn func(changed []string)) error {
+	for {
+		time.Sleep(interval)
+		changed, err := w.Poll()
+		if err != nil {
+			return err
+		}
+		if len(changed) > 0 {
+			fn(changed)
+		}
+	}
+}
+
+// Reload discards the project's contents and loads and parses it from
+// scratch, keeping its settings.
+func (p *Project) Reload() error {
+	var np Project
+
+	switch p.Kind {
+	case PK_SCRIPT:
+		np = SingleFileFS(p.FS, p.File)
+	case PK_CODE:
+		return nil
+	default:
+		var err error
+		np, err = LoadProjectFS(p.FS, p.File)
+		if err != nil {
+			return err
+		}
+	}
+
+	np.Jobs = p.Jobs
+	np.Cache = p.Cache
+	np.Parse()
+	*p = np
+	return nil
+}
+
+// resourceFor finds the index of the resource that owns file_path,
+// either as its .yy file or as one of its scripts.
+func (p *Project) resourceFor(file_path string) int {
+	owner := p.Owner(file_path)
+	if owner == nil {
+		return -1
+	}
+	return slices.Index(p.Resources, owner)
+}
+
+func sameMacro(a, b parser.Macro) bool {
+	if a.Config != b.Config || len(a.Value) != len(b.Value) {
+		return false
+	}
+	for i := range a.Value {
+		if a.Value[i].Type != b.Value[i].Type || a.Value[i].Value != b.Value[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// changedMacros lists the names of macros that were added, removed or
+// redefined between two macro environments.
+func changedMacros(before, after map[string]parser.Macro) []string {
+	out := make([]string, 0)
+	for name, m := range after {
+		old, ok := before[name]
+		if !ok || !sameMacro(old, m) {
+			out = append(out, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// expandingTo adds to names the macros that expand to one of them, directly
+// or through other macros, in any of the macro environments.
+func expandingTo(names []string, envs ...map[string]parser.Macro) []string {
+	out := slices.Clone(names)
+	for grew := true; grew; {
+		grew = false
+		for _, env := range envs {
+			for name, m := range env {
+				if slices.Contains(out, name) {
+					continue
+				}
+				for _, t := range m.Value {
+					if t.Type == parser.T_IDENT && slices.Contains(out, t.Value) {
+						out = append(out, name)
+						grew = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Update brings the project up to date after the given files changed.
+//
+// Only the resources owning those files are reloaded and reparsed. If
+// that changes any #macro definitions, every other script that mentions
+// one of the affected macros, or a macro that expands to one of them, is
+// reparsed as well. Changes to the
+// project file, or to .yy files the project doesn't know about, fall
+// back to a full Reload.
+func (p *Project) Update(changed []string) error {
+	if p.Kind != PK_PROJECT {
+		return p.Reload()
+	}
+
+	affected := make([]int, 0)
+	for _, file_path := range changed {
+		if file_path == p.File {
+			return p.Reload()
+		}
+
+		i := p.resourceFor(file_path)
+		if i < 0 {
+			if strings.HasSuffix(file_path, ".yy") {
+				return p.Reload()
+			}
+			// A .gml file that isn't part of the project.
+			continue
+		}
+		if !slices.Contains(affected, i) {
+			affected = append(affected, i)
+		}
+	}
+
+	reparse := make([]*ResGMScript, 0)
+	for _, i := range affected {
+		res, err := loadResource(p.FS, p.Resources[i].GetPath())
+		if err != nil {
+			return p.Reload()
+		}
+		p.Resources[i] = res
+		p.index = nil
+
+		if cr, ok := res.(codeResource); ok {
+			for _, scr := range cr.Scripts() {
+				scr.Macros = scr.preprocess()
+				reparse = append(reparse, scr)
+			}
+		}
+	}
+
+	scripts := p.Scripts()
+	all_macros := make([]map[string]parser.Macro, len(scripts))
+	for i, scr := range scripts {
+		all_macros[i] = scr.Macros
+	}
+	macros := utils.MapMerge(all_macros...)
+
+	changed_macros := expandingTo(changedMacros(p.Macros, macros), p.Macros, macros)
+	for _, scr := range scripts {
+		if slices.Contains(reparse, scr) {
+			continue
+		}
+		for _, name := range changed_macros {
+			if strings.Contains(scr.Script, name) {
+				scr.preprocess()
+				reparse = append(reparse, scr)
+				break
+			}
+		}
+	}
+	p.Macros = macros
+
+	utils.ParallelFor(len(reparse), max(p.Jobs, 1), func(i int) {
+		reparse[i].injectMacros(p.Macros)
+		reparse[i].parseAST()
+	})
+
+	return nil
+}
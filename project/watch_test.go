@@ -0,0 +1,70 @@
+package project
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUpdateMacroDependents(t *testing.T) {
+	fsys := memProject(
+		"scr_m", "#macro A 1\n#macro B A",
+		"scr_b", "var b = B;",
+		"scr_other", "var c = 2;",
+	)
+	p := loadMemProject(t, fsys)
+	p.Parse()
+	if n := p.ErrorCount(); n != 0 {
+		t.Fatalf("got %v errors: %v", n, p.AllErrors())
+	}
+	other := p.Scripts()[2].Ast.Children[0]
+
+	// scr_b only mentions B, which expands to the changed A.
+	fsys["scripts/scr_m/scr_m.gml"] = []byte("#macro A +\n#macro B A")
+	err := p.Update([]string{"scripts/scr_m/scr_m.gml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := p.Scripts()[1]; len(b.Errors) == 0 {
+		t.Errorf("%v wasn't reparsed after the macro it uses changed", b.GMLPath)
+	}
+	if p.Scripts()[2].Ast.Children[0] != other {
+		t.Errorf("a script that doesn't use the macros was reparsed")
+	}
+
+	fsys["scripts/scr_m/scr_m.gml"] = []byte("#macro A 2\n#macro B A")
+	err = p.Update([]string{"scripts/scr_m/scr_m.gml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := p.ErrorCount(); n != 0 {
+		t.Errorf("got %v errors after fixing the macro: %v", n, p.AllErrors())
+	}
+}
+
+func TestPollNewResource(t *testing.T) {
+	fsys := memProject("scr_a", "var a = 1;")
+	p := loadMemProject(t, fsys)
+	p.Parse()
+	w := NewWatcher(&p)
+
+	if changed, err := w.Poll(); err != nil || len(changed) != 0 {
+		t.Errorf("got %v, %v without changes", changed, err)
+	}
+
+	// A resource the project doesn't know yet reloads it.
+	with_b := memProject("scr_a", "var a = 1;", "scr_b", "var b = ;")
+	for name, data := range with_b {
+		fsys[name] = data
+	}
+	changed, err := w.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"scripts/scr_b/scr_b.gml", "scripts/scr_b/scr_b.yy"}
+	if !slices.Equal(changed, want) {
+		t.Errorf("got changes %v, want %v", changed, want)
+	}
+	if len(p.Scripts()) != 2 || p.ErrorCount() == 0 {
+		t.Errorf("the new script wasn't loaded and parsed")
+	}
+}
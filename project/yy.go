@@ -0,0 +1,638 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// .yy and .yyp files look like JSON, but GameMaker writes them with
+// trailing commas and a fixed layout: most objects are spread over
+// several lines, while small ones (resource references, the entries of
+// the .yyp resource list, ...) are written on a single line, and some
+// (extension files) mix the two. The types below remember the exact
+// whitespace around everything they parse, so an unmodified document is
+// written back byte for byte and a modified one only differs where it
+// was changed. Values created from scratch are laid out following the
+// style detected in the rest of the document.
+
+type YY_KIND int
+
+const (
+	// YY_RAW values are strings, numbers, booleans and null. They are
+	// kept exactly as they were written.
+	YY_RAW YY_KIND = iota
+	YY_OBJECT
+	YY_ARRAY
+)
+
+type YYField struct {
+	Key   string
+	Value *YYValue
+}
+
+type YYValue struct {
+	Kind   YY_KIND
+	Raw    string
+	Fields []YYField
+	Items  []*YYValue
+	// Inline values are written on a single line. Everything nested in
+	// an inline value is inline as well.
+	Inline bool
+	// item is where the value sits in its parent, close is the
+	// whitespace before its closing bracket and trailing is set if its
+	// last item is followed by a comma. They are only known for values
+	// that were parsed.
+	item     yyPlacement
+	close    string
+	closed   bool
+	trailing bool
+}
+
+// yyPlacement is the text surrounding an item of an object or array:
+// the whitespace before it (before the key, for fields), everything
+// between a field's key and its value, and the whitespace after it.
+type yyPlacement struct {
+	placed bool
+	lead   string
+	colon  string
+	trail  string
+	comma  bool
+}
+
+type YYDocument struct {
+	Root *YYValue
+	// Newline is either "\n" or "\r\n".
+	Newline string
+	// Indent is the indentation added for each level of nesting.
+	Indent string
+	// Spaced documents have a space after the colon of fields that are
+	// on their own line, i.e. `"name": "x"` rather than `"name":"x"`.
+	Spaced bool
+	// Trailing documents put a comma after the last item of every
+	// object and array.
+	Trailing bool
+	// FinalNewline is set when the document ends with a newline.
+	FinalNewline bool
+}
+
+func YYString(s string) *YYValue {
+	b := bytes.Buffer{}
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return &YYValue{Kind: YY_RAW, Raw: strings.TrimSuffix(b.String(), "\n")}
+}
+
+func YYInt(n int) *YYValue {
+	return &YYValue{Kind: YY_RAW, Raw: strconv.Itoa(n)}
+}
+
+func YYBool(b bool) *YYValue {
+	return &YYValue{Kind: YY_RAW, Raw: strconv.FormatBool(b)}
+}
+
+func YYNull() *YYValue {
+	return &YYValue{Kind: YY_RAW, Raw: "null"}
+}
+
+func YYObject(inline bool, fields ...YYField) *YYValue {
+	return &YYValue{Kind: YY_OBJECT, Fields: fields, Inline: inline}
+}
+
+func YYArray(inline bool, items ...*YYValue) *YYValue {
+	return &YYValue{Kind: YY_ARRAY, Items: items, Inline: inline}
+}
+
+// YYRef builds a reference to another resource or folder, the way
+// GameMaker writes "parent", "spriteId" and friends.
+func YYRef(name string, ref_path string) *YYValue {
+	return YYObject(true,
+		YYField{"name", YYString(name)},
+		YYField{"path", YYString(ref_path)},
+	)
+}
+
+// IsNull reports whether v is missing or null.
+func (v *YYValue) IsNull() bool {
+	return v == nil || (v.Kind == YY_RAW && v.Raw == "null")
+}
+
+// Str decodes a string value. Anything else decodes to "".
+func (v *YYValue) Str() string {
+	if v == nil || v.Kind != YY_RAW {
+		return ""
+	}
+	var s string
+	if json.Unmarshal([]byte(v.Raw), &s) != nil {
+		return ""
+	}
+	return s
+}
+
+// Int decodes a numeric value. Anything else decodes to 0.
+func (v *YYValue) Int() int {
+	if v == nil || v.Kind != YY_RAW {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v.Raw, 64)
+	if err != nil {
+		return 0
+	}
+	return int(f)
+}
+
+// Get returns the value of an object's field, or nil.
+func (v *YYValue) Get(key string) *YYValue {
+	if v == nil || v.Kind != YY_OBJECT {
+		return nil
+	}
+	for _, f := range v.Fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// Has reports whether an object has the given field.
+func (v *YYValue) Has(key string) bool {
+	return v.Get(key) != nil
+}
+
+// yyKeyLess orders keys the way GameMaker sorts them in the newer
+// project format: case-insensitively, which also puts "$GM..." and
+// "%Name" first.
+func yyKeyLess(a, b string) bool {
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+func (v *YYValue) sortedKeys() bool {
+	if len(v.Fields) < 2 {
+		return false
+	}
+	for i := 1; i < len(v.Fields); i++ {
+		if yyKeyLess(v.Fields[i].Key, v.Fields[i-1].Key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Set replaces the value of an object's field. A new field is inserted
+// in key order if the object's keys are sorted, and appended otherwise.
+func (v *YYValue) Set(key string, value *YYValue) {
+	for i, f := range v.Fields {
+		if f.Key == key {
+			value.item = f.Value.item
+			v.Fields[i].Value = value
+			return
+		}
+	}
+
+	field := YYField{key, value}
+	if v.sortedKeys() {
+		for i, f := range v.Fields {
+			if yyKeyLess(key, f.Key) {
+				v.Fields = append(v.Fields[:i], append([]YYField{field}, v.Fields[i:]...)...)
+				return
+			}
+		}
+	}
+	v.Fields = append(v.Fields, field)
+}
+
+// Delete removes an object's field, if it has it.
+func (v *YYValue) Delete(key string) {
+	for i, f := range v.Fields {
+		if f.Key == key {
+			v.Fields = append(v.Fields[:i], v.Fields[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetName renames a resource, updating "%Name" as well as "name" when
+// the document uses the newer format.
+func (v *YYValue) SetName(name string) {
+	if v.Has("%Name") {
+		v.Set("%Name", YYString(name))
+	}
+	v.Set("name", YYString(name))
+}
+
+// Clone makes a deep copy of v.
+func (v *YYValue) Clone() *YYValue {
+	if v == nil {
+		return nil
+	}
+	out := &YYValue{
+		Kind:     v.Kind,
+		Raw:      v.Raw,
+		Inline:   v.Inline,
+		item:     v.item,
+		close:    v.close,
+		closed:   v.closed,
+		trailing: v.trailing,
+	}
+	if v.Fields != nil {
+		out.Fields = make([]YYField, len(v.Fields))
+		for i, f := range v.Fields {
+			out.Fields[i] = YYField{f.Key, f.Value.Clone()}
+		}
+	}
+	if v.Items != nil {
+		out.Items = make([]*YYValue, len(v.Items))
+		for i, it := range v.Items {
+			out.Items[i] = it.Clone()
+		}
+	}
+	return out
+}
+
+type yyParser struct {
+	src   []byte
+	pos   int
+	doc   *YYDocument
+	style bool
+	comma bool
+}
+
+func (ps *yyParser) errorf(format string, args ...any) error {
+	line := bytes.Count(ps.src[:min(ps.pos, len(ps.src))], []byte{'\n'}) + 1
+	return fmt.Errorf("line %v: %v", line, fmt.Sprintf(format, args...))
+}
+
+// skip moves past whitespace and returns it.
+func (ps *yyParser) skip() string {
+	start := ps.pos
+	for ps.pos < len(ps.src) {
+		switch ps.src[ps.pos] {
+		case ' ', '\t', '\r', '\n':
+			ps.pos++
+		default:
+			return string(ps.src[start:ps.pos])
+		}
+	}
+	return string(ps.src[start:ps.pos])
+}
+
+func (ps *yyParser) peek() byte {
+	if ps.pos >= len(ps.src) {
+		return 0
+	}
+	return ps.src[ps.pos]
+}
+
+func (ps *yyParser) string() (string, error) {
+	start := ps.pos
+	ps.pos++
+	for ps.pos < len(ps.src) {
+		switch ps.src[ps.pos] {
+		case '\\':
+			ps.pos += 2
+			continue
+		case '"':
+			ps.pos++
+			return string(ps.src[start:ps.pos]), nil
+		}
+		ps.pos++
+	}
+	return "", ps.errorf("unterminated string")
+}
+
+func (ps *yyParser) scalar() (string, error) {
+	start := ps.pos
+	for ps.pos < len(ps.src) {
+		c := ps.src[ps.pos]
+		if c == ',' || c == ']' || c == '}' || c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			break
+		}
+		ps.pos++
+	}
+	if start == ps.pos {
+		return "", ps.errorf("unexpected %q", ps.peek())
+	}
+	return string(ps.src[start:ps.pos]), nil
+}
+
+// separator consumes the whitespace and comma after an item, recording
+// them in its placement.
+func (ps *yyParser) separator(item *yyPlacement, closing byte) error {
+	item.trail = ps.skip()
+	if ps.peek() == ',' {
+		ps.pos++
+		item.comma = true
+		return nil
+	}
+	if ps.peek() == closing {
+		return nil
+	}
+	return ps.errorf("expected ',' or %q", closing)
+}
+
+// detectStyle records how fields on their own lines are indented and
+// spaced, based on the first one in the document.
+func (ps *yyParser) detectStyle(lead string, colon string) {
+	if ps.style {
+		return
+	}
+	nl := strings.LastIndexByte(lead, '\n')
+	if nl < 0 {
+		return
+	}
+	ps.doc.Indent = lead[nl+1:]
+	ps.doc.Spaced = strings.HasSuffix(colon, " ")
+	ps.style = true
+}
+
+// container parses the items of an object or array up to the closing
+// bracket, calling item for each one after reading its leading
+// whitespace.
+func (ps *yyParser) container(v *YYValue, closing byte, item func(lead string) (*YYValue, error)) error {
+	start := ps.pos
+	ps.pos++
+	var last *YYValue
+
+	for {
+		lead := ps.skip()
+		if ps.peek() == closing {
+			// The space inside an empty container says nothing about
+			// where its closing bracket goes once it has items. Without a
+			// trailing comma, the space after the last item is really the
+			// space before the bracket, whichever item ends up last.
+			if last != nil {
+				v.close = lead
+				v.closed = true
+				v.trailing = last.item.comma
+				if !last.item.comma {
+					v.close = last.item.trail + lead
+					last.item.trail = ""
+				}
+			}
+			break
+		}
+		if last != nil && !last.item.comma {
+			return ps.errorf("expected ',' or %q", closing)
+		}
+
+		val, err := item(lead)
+		if err != nil {
+			return err
+		}
+		val.item.placed = true
+		val.item.lead = lead
+		if err := ps.separator(&val.item, closing); err != nil {
+			return err
+		}
+		last = val
+	}
+
+	if last != nil && last.item.comma {
+		ps.comma = true
+	}
+	ps.pos++
+	v.Inline = bytes.IndexByte(ps.src[start:ps.pos], '\n') < 0
+	return nil
+}
+
+func (ps *yyParser) value() (*YYValue, error) {
+	switch ps.peek() {
+	case '{':
+		v := &YYValue{Kind: YY_OBJECT, Fields: make([]YYField, 0)}
+		err := ps.container(v, '}', func(lead string) (*YYValue, error) {
+			if ps.peek() != '"' {
+				return nil, ps.errorf("expected a key")
+			}
+			raw, err := ps.string()
+			if err != nil {
+				return nil, err
+			}
+			var key string
+			if json.Unmarshal([]byte(raw), &key) != nil {
+				return nil, ps.errorf("invalid key %v", raw)
+			}
+
+			colon_start := ps.pos
+			ps.skip()
+			if ps.peek() != ':' {
+				return nil, ps.errorf("expected ':' after %v", raw)
+			}
+			ps.pos++
+			ps.skip()
+			colon := string(ps.src[colon_start:ps.pos])
+			ps.detectStyle(lead, colon)
+
+			val, err := ps.value()
+			if err != nil {
+				return nil, err
+			}
+			val.item.colon = colon
+			v.Fields = append(v.Fields, YYField{key, val})
+			return val, nil
+		})
+		return v, err
+
+	case '[':
+		v := &YYValue{Kind: YY_ARRAY, Items: make([]*YYValue, 0)}
+		err := ps.container(v, ']', func(lead string) (*YYValue, error) {
+			val, err := ps.value()
+			if err != nil {
+				return nil, err
+			}
+			v.Items = append(v.Items, val)
+			return val, nil
+		})
+		return v, err
+
+	case '"':
+		raw, err := ps.string()
+		if err != nil {
+			return nil, err
+		}
+		return &YYValue{Kind: YY_RAW, Raw: raw}, nil
+
+	case 0:
+		return nil, ps.errorf("unexpected end of file")
+	}
+
+	raw, err := ps.scalar()
+	if err != nil {
+		return nil, err
+	}
+	return &YYValue{Kind: YY_RAW, Raw: raw}, nil
+}
+
+// ParseYY reads a .yy or .yyp file, remembering its formatting.
+func ParseYY(src []byte) (*YYDocument, error) {
+	doc := &YYDocument{
+		Newline:  "\n",
+		Indent:   "  ",
+		Trailing: true,
+	}
+	if bytes.Contains(src, []byte("\r\n")) {
+		doc.Newline = "\r\n"
+	}
+
+	ps := yyParser{src: src, doc: doc}
+	ps.skip()
+	root, err := ps.value()
+	if err != nil {
+		return nil, err
+	}
+	ps.skip()
+	if ps.pos != len(src) {
+		return nil, ps.errorf("unexpected content after the end of the document")
+	}
+
+	doc.Root = root
+	doc.Trailing = ps.comma || !yyHasItems(root)
+	doc.FinalNewline = bytes.HasSuffix(src, []byte("\n"))
+	return doc, nil
+}
+
+func yyHasItems(v *YYValue) bool {
+	return len(v.Fields) > 0 || len(v.Items) > 0
+}
+
+type yyWriter struct {
+	doc *YYDocument
+	b   strings.Builder
+}
+
+func (w *yyWriter) indent(depth int) {
+	for i := 0; i < depth; i++ {
+		w.b.WriteString(w.doc.Indent)
+	}
+}
+
+// item writes one item of a container. Parsed items are written with
+// their original surroundings; new ones copy the surroundings of the
+// previous item if it was parsed, and follow the document's style if
+// not. The last item is followed by a comma if the container's last item
+// was when it was parsed, whichever item that was.
+func (w *yyWriter) item(key *string, v *YYValue, prev *YYValue, last bool, trailing bool, depth int, inline bool) {
+	place := v.item
+	if !place.placed {
+		place = yyPlacement{}
+		if prev != nil && prev.item.placed {
+			place.placed = true
+			place.lead = prev.item.lead
+			place.colon = prev.item.colon
+		}
+	}
+
+	if place.placed {
+		w.b.WriteString(place.lead)
+	} else if !inline {
+		w.b.WriteString(w.doc.Newline)
+		w.indent(depth + 1)
+	}
+
+	if key != nil {
+		w.b.WriteString(YYString(*key).Raw)
+		if place.placed {
+			w.b.WriteString(place.colon)
+		} else {
+			w.b.WriteByte(':')
+			if !inline && w.doc.Spaced {
+				w.b.WriteByte(' ')
+			}
+		}
+	}
+
+	w.value(v, depth+1, inline)
+	w.b.WriteString(place.trail)
+	if !last || trailing {
+		w.b.WriteByte(',')
+	}
+}
+
+// trailing reports whether the last item of a container is followed by a
+// comma.
+func (w *yyWriter) trailing(v *YYValue) bool {
+	if v.closed {
+		return v.trailing
+	}
+	return w.doc.Trailing
+}
+
+func (w *yyWriter) end(v *YYValue, depth int, inline bool) {
+	if v.closed {
+		w.b.WriteString(v.close)
+	} else if !inline {
+		w.b.WriteString(w.doc.Newline)
+		w.indent(depth)
+	}
+}
+
+func (w *yyWriter) value(v *YYValue, depth int, inline bool) {
+	inline = inline || v.Inline
+
+	switch v.Kind {
+	case YY_RAW:
+		w.b.WriteString(v.Raw)
+
+	case YY_OBJECT:
+		if len(v.Fields) == 0 {
+			w.b.WriteString("{}")
+			return
+		}
+		w.b.WriteByte('{')
+		var prev *YYValue
+		for i, f := range v.Fields {
+			w.item(&f.Key, f.Value, prev, i == len(v.Fields)-1, w.trailing(v), depth, inline)
+			prev = f.Value
+		}
+		w.end(v, depth, inline)
+		w.b.WriteByte('}')
+
+	case YY_ARRAY:
+		if len(v.Items) == 0 {
+			w.b.WriteString("[]")
+			return
+		}
+		w.b.WriteByte('[')
+		var prev *YYValue
+		for i, it := range v.Items {
+			w.item(nil, it, prev, i == len(v.Items)-1, w.trailing(v), depth, inline)
+			prev = it
+		}
+		w.end(v, depth, inline)
+		w.b.WriteByte(']')
+	}
+}
+
+// Bytes formats the document the way it was read.
+func (d *YYDocument) Bytes() []byte {
+	w := yyWriter{doc: d}
+	w.value(d.Root, 0, false)
+	if d.FinalNewline {
+		w.b.WriteString(d.Newline)
+	}
+	return []byte(w.b.String())
+}
+
+// ReadYY loads and parses a .yy or .yyp file.
+func ReadYY(fsys FS, file_path string) (*YYDocument, error) {
+	b, err := fsys.ReadFile(file_path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseYY(b)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", file_path, err)
+	}
+	return doc, nil
+}
+
+// WriteYY formats and writes a .yy or .yyp file.
+func WriteYY(fsys WriteFS, file_path string, doc *YYDocument) error {
+	if doc == nil || doc.Root == nil {
+		return errors.New("cannot write an empty document")
+	}
+	return fsys.WriteFile(file_path, doc.Bytes())
+}
@@ -0,0 +1,182 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var yyFixtures = []string{
+	"obj_2_3.yy",
+	"obj_2024.yy",
+	"obj_crlf.yy",
+	"scr_2024.yy",
+	"ext_2_3.yy",
+	"project_2_3.yyp",
+	"project_2024.yyp",
+	"plain.yy",
+}
+
+func readYYFixture(t *testing.T, name string) (string, *YYDocument) {
+	t.Helper()
+	b, err := os.ReadFile(path.Join("testdata", "yy", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ParseYY(b)
+	if err != nil {
+		t.Fatalf("%v: %v", name, err)
+	}
+	return string(b), doc
+}
+
+func yyLines(s string) []string {
+	return strings.SplitAfter(s, "\n")
+}
+
+// checkLines fails unless got is want with the lines from start to end
+// replaced by lines.
+func checkLines(t *testing.T, want string, got string, start int, end int, lines ...string) {
+	t.Helper()
+	expected := slices.Replace(yyLines(want), start, end, lines...)
+	if got != strings.Join(expected, "") {
+		t.Errorf("got:\n%v\nwant:\n%v", got, strings.Join(expected, ""))
+	}
+}
+
+func TestYYRoundTrip(t *testing.T) {
+	for _, name := range yyFixtures {
+		src, doc := readYYFixture(t, name)
+		if got := string(doc.Bytes()); got != src {
+			t.Errorf("%v isn't written back as it was read:\n%v", name, got)
+		}
+	}
+}
+
+func TestYYStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		newline  string
+		spaced   bool
+		trailing bool
+	}{
+		{"obj_2_3.yy", "\n", true, true},
+		{"obj_2024.yy", "\n", false, true},
+		{"obj_crlf.yy", "\r\n", true, true},
+		{"plain.yy", "\n", true, false},
+	}
+	for _, test := range tests {
+		_, doc := readYYFixture(t, test.name)
+		if doc.Newline != test.newline || doc.Spaced != test.spaced || doc.Trailing != test.trailing {
+			t.Errorf("%v: got newline %q, spaced %v, trailing %v", test.name, doc.Newline, doc.Spaced, doc.Trailing)
+		}
+	}
+}
+
+func TestYYSetExisting(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		line int
+		want string
+	}{
+		{"obj_2_3.yy", "name", 19, `  "name": "obj_b",` + "\n"},
+		{"obj_crlf.yy", "name", 19, `  "name": "obj_b",` + "\r\n"},
+		{"obj_2024.yy", "name", 8, `  "name":"obj_b",` + "\n"},
+		{"plain.yy", "name", 1, `  "name": "obj_b",` + "\n"},
+	}
+	for _, test := range tests {
+		src, doc := readYYFixture(t, test.name)
+		doc.Root.Set(test.key, YYString("obj_b"))
+		checkLines(t, src, string(doc.Bytes()), test.line, test.line+1, test.want)
+	}
+}
+
+func TestYYSetName(t *testing.T) {
+	src, doc := readYYFixture(t, "scr_2024.yy")
+	doc.Root.SetName("scr_walk")
+	checkLines(t, src, string(doc.Bytes()), 2, 6,
+		`  "%Name":"scr_walk",`+"\n",
+		`  "isCompatibility":false,`+"\n",
+		`  "isDnD":false,`+"\n",
+		`  "name":"scr_walk",`+"\n",
+	)
+}
+
+func TestYYSetNew(t *testing.T) {
+	// Sorted keys get the new one in order, others at the end.
+	src, doc := readYYFixture(t, "obj_2024.yy")
+	doc.Root.Set("mode", YYInt(2))
+	checkLines(t, src, string(doc.Bytes()), 8, 8, `  "mode":2,`+"\n")
+
+	src, doc = readYYFixture(t, "obj_2_3.yy")
+	doc.Root.Set("zoom", YYBool(true))
+	checkLines(t, src, string(doc.Bytes()), 22, 22, `  "zoom": true,`+"\n")
+
+	src, doc = readYYFixture(t, "obj_crlf.yy")
+	doc.Root.Set("zoom", YYRef("Objects", "folders/Objects.yy"))
+	checkLines(t, src, string(doc.Bytes()), 22, 22, `  "zoom": {"name":"Objects","path":"folders/Objects.yy",},`+"\r\n")
+}
+
+func TestYYDelete(t *testing.T) {
+	src, doc := readYYFixture(t, "obj_2_3.yy")
+	doc.Root.Delete("solid")
+	checkLines(t, src, string(doc.Bytes()), 2, 3)
+
+	// Objects spread over several lines go as a whole.
+	src, doc = readYYFixture(t, "obj_2024.yy")
+	doc.Root.Delete("parent")
+	checkLines(t, src, string(doc.Bytes()), 10, 14)
+
+	src, doc = readYYFixture(t, "obj_crlf.yy")
+	doc.Root.Delete("resourceType")
+	checkLines(t, src, string(doc.Bytes()), 21, 22)
+
+	// Items of arrays, too.
+	src, doc = readYYFixture(t, "project_2_3.yyp")
+	resources := doc.Root.Get("resources")
+	resources.Items = slices.Delete(resources.Items, 1, 2)
+	checkLines(t, src, string(doc.Bytes()), 3, 4)
+}
+
+func TestYYDeleteLast(t *testing.T) {
+	// Without trailing commas, the item that becomes the last one loses
+	// its comma...
+	src, doc := readYYFixture(t, "plain.yy")
+	doc.Root.Delete("last")
+	got := string(doc.Bytes())
+	checkLines(t, src, got, 6, 8, `  "inline": {"x": 1, "y": 2}`+"\n")
+	if !json.Valid([]byte(got)) {
+		t.Errorf("not valid JSON:\n%v", got)
+	}
+
+	src, doc = readYYFixture(t, "plain.yy")
+	tags := doc.Root.Get("tags")
+	tags.Items = tags.Items[:1]
+	checkLines(t, src, string(doc.Bytes()), 3, 5, `    "a"`+"\n")
+
+	src, doc = readYYFixture(t, "plain.yy")
+	doc.Root.Get("inline").Delete("y")
+	checkLines(t, src, string(doc.Bytes()), 6, 7, `  "inline": {"x": 1},`+"\n")
+
+	// ...and with them, it keeps it.
+	src, doc = readYYFixture(t, "obj_2024.yy")
+	doc.Root.Delete("visible")
+	checkLines(t, src, string(doc.Bytes()), 36, 37)
+
+	src, doc = readYYFixture(t, "ext_2_3.yy")
+	files := doc.Root.Get("files").Items[0]
+	fns := files.Get("functions")
+	fns.Items = fns.Items[:1]
+	checkLines(t, src, string(doc.Bytes()), 9, 10)
+}
+
+func TestYYAppend(t *testing.T) {
+	src, doc := readYYFixture(t, "plain.yy")
+	tags := doc.Root.Get("tags")
+	tags.Items = append(tags.Items, YYString("c"))
+	checkLines(t, src, string(doc.Bytes()), 4, 5, `    "b",`+"\n", `    "c"`+"\n")
+}
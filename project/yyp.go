@@ -0,0 +1,257 @@
+package project
+
+import (
+	"fmt"
+	"gmtc/utils"
+	"path"
+	"slices"
+	"strings"
+)
+
+type MergeConflictError struct{ error }
+
+// yypLists are the lists of a .yyp whose entries can be told apart by a
+// key, mapped to a function that returns that key. Entries with the same
+// key are the same thing, possibly in different versions.
+var yypLists = map[string]func(v *YYValue) string{
+	"resources":      func(v *YYValue) string { return v.Get("id").Get("name").Str() },
+	"Folders":        func(v *YYValue) string { return v.Get("folderPath").Str() },
+	"RoomOrderNodes": func(v *YYValue) string { return v.Get("roomId").Get("name").Str() },
+	"Options":        func(v *YYValue) string { return v.Get("name").Str() },
+	"AudioGroups":    func(v *YYValue) string { return v.Get("name").Str() },
+	"TextureGroups":  func(v *YYValue) string { return v.Get("name").Str() },
+	"IncludedFiles": func(v *YYValue) string {
+		return path.Join(v.Get("filePath").Str(), v.Get("name").Str())
+	},
+}
+
+// yypSorted are the lists NormaliseYYP sorts. The order of the others
+// means something to GameMaker (room order, for one), or they are
+// rarely edited.
+var yypSorted = []string{"resources", "Folders"}
+
+// yyEqual reports whether two values hold the same data, ignoring
+// formatting and the order of object fields.
+func yyEqual(a, b *YYValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case YY_OBJECT:
+		if len(a.Fields) != len(b.Fields) {
+			return false
+		}
+		for _, f := range a.Fields {
+			if !yyEqual(f.Value, b.Get(f.Key)) {
+				return false
+			}
+		}
+		return true
+	case YY_ARRAY:
+		return slices.EqualFunc(a.Items, b.Items, yyEqual)
+	}
+	return a.Raw == b.Raw
+}
+
+// reformat forgets how v and everything in it was laid out, so that it
+// is written in the document's style.
+func (v *YYValue) reformat(inline bool) {
+	v.item = yyPlacement{}
+	v.close = ""
+	v.closed = false
+	if v.Kind != YY_RAW {
+		v.Inline = inline
+	}
+	for _, f := range v.Fields {
+		f.Value.reformat(inline)
+	}
+	for _, it := range v.Items {
+		it.reformat(inline)
+	}
+}
+
+// NormaliseYYP puts a .yyp in a canonical form, so that two people
+// adding resources produce the same file and the lists merge cleanly:
+// resources and folders are sorted by name, exact duplicates are
+// dropped, and the top level fields and every entry of the lists GameMaker
+// keeps one per line are laid out the same way. Anything else is left
+// as it is.
+func NormaliseYYP(doc *YYDocument) {
+	doc.Trailing = true
+	doc.FinalNewline = true
+
+	for _, f := range doc.Root.Fields {
+		val := f.Value
+		val.item = yyPlacement{}
+
+		key, ok := yypLists[f.Key]
+		if !ok || val.Kind != YY_ARRAY {
+			continue
+		}
+
+		val.Inline = false
+		val.close = ""
+		val.closed = false
+		for _, it := range val.Items {
+			it.reformat(true)
+		}
+
+		if !slices.Contains(yypSorted, f.Key) {
+			continue
+		}
+
+		slices.SortStableFunc(val.Items, func(a, b *YYValue) int {
+			ka, kb := key(a), key(b)
+			if c := strings.Compare(strings.ToLower(ka), strings.ToLower(kb)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(ka, kb); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Get("id").Get("path").Str(), b.Get("id").Get("path").Str())
+		})
+		val.Items = slices.CompactFunc(val.Items, yyEqual)
+	}
+}
+
+// yyEntryEqual is yyEqual for entries of the lists in yypLists, which
+// ignores their order field: GameMaker renumbers it as resources are
+// moved around, and it says nothing about the entry itself.
+func yyEntryEqual(a, b *YYValue) bool {
+	if a == nil || b == nil || a.Kind != YY_OBJECT || b.Kind != YY_OBJECT {
+		return yyEqual(a, b)
+	}
+	return yyEqual(withoutOrder(a), withoutOrder(b))
+}
+
+func withoutOrder(v *YYValue) *YYValue {
+	fields := slices.DeleteFunc(slices.Clone(v.Fields), func(f YYField) bool { return f.Key == "order" })
+	return &YYValue{Kind: YY_OBJECT, Fields: fields}
+}
+
+// merge3 merges one value given its base version and both sides', any of
+// which can be nil for a value that isn't there. A side that left the
+// value as it was in the base takes the other side's change, including
+// its removal. ok is false if both sides changed it, differently; ours
+// is kept then, unless ours removed it.
+func merge3(base, ours, theirs *YYValue, equal func(a, b *YYValue) bool) (out *YYValue, ok bool) {
+	switch {
+	case equal(ours, theirs):
+		return ours, true
+	case equal(base, ours):
+		return theirs, true
+	case equal(base, theirs):
+		return ours, true
+	}
+	if ours == nil {
+		return theirs, false
+	}
+	return ours, false
+}
+
+// conflict describes a value merge3 couldn't merge.
+func conflict(what string, ours *YYValue, theirs *YYValue) error {
+	switch {
+	case ours == nil:
+		return MergeConflictError{fmt.Errorf("%v was removed in ours and changed in theirs, keeping theirs", what)}
+	case theirs == nil:
+		return MergeConflictError{fmt.Errorf("%v was removed in theirs and changed in ours, keeping ours", what)}
+	}
+	return MergeConflictError{fmt.Errorf("%v was changed differently in the two versions, keeping ours", what)}
+}
+
+// mergeList merges the entries of one of the lists in yypLists, each on
+// its own, in the order of ours with entries only theirs has at the end.
+func mergeList(name string, key func(v *YYValue) string, base, ours, theirs *YYValue) (*YYValue, utils.Errors) {
+	errs := make(utils.Errors, 0)
+	entries := func(list *YYValue) map[string]*YYValue {
+		out := make(map[string]*YYValue)
+		if list != nil {
+			for _, it := range list.Items {
+				out[key(it)] = it
+			}
+		}
+		return out
+	}
+	base_entries, our_entries, their_entries := entries(base), entries(ours), entries(theirs)
+
+	out := ours.Clone()
+	out.Items = make([]*YYValue, 0, len(ours.Items))
+	done := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, list := range []*YYValue{ours, theirs} {
+		for _, it := range list.Items {
+			keys = append(keys, key(it))
+		}
+	}
+	for _, k := range keys {
+		if done[k] {
+			continue
+		}
+		done[k] = true
+		o, t := our_entries[k], their_entries[k]
+		merged, ok := merge3(base_entries[k], o, t, yyEntryEqual)
+		if !ok {
+			errs = errs.Add(conflict(name+": "+k, o, t))
+		}
+		if merged != nil {
+			out.Items = append(out.Items, merged.Clone())
+		}
+	}
+	return out, errs
+}
+
+// MergeYYP combines two versions of a .yyp that were both changed from
+// base, such as both sides of a merge conflict. Each side's changes are
+// kept: lists of resources, folders and the like are merged entry by
+// entry, so an entry one side removed stays removed, and every other top
+// level field as a whole. Where both sides changed the same thing
+// differently, the result keeps ours and the conflict is reported as a
+// MergeConflictError. base can be nil if there is no common version.
+// The result is normalised.
+func MergeYYP(base *YYDocument, ours *YYDocument, theirs *YYDocument) (*YYDocument, utils.Errors) {
+	errs := make(utils.Errors, 0)
+	base_root := YYObject(false)
+	if base != nil {
+		base_root = base.Root
+	}
+	out := *ours
+	out.Root = ours.Root.Clone()
+	out.Root.Fields = make([]YYField, 0, len(ours.Root.Fields))
+
+	keys := make([]string, 0)
+	for _, doc := range []*YYDocument{ours, theirs} {
+		for _, f := range doc.Root.Fields {
+			if !slices.Contains(keys, f.Key) {
+				keys = append(keys, f.Key)
+			}
+		}
+	}
+
+	for _, k := range keys {
+		b, o, t := base_root.Get(k), ours.Root.Get(k), theirs.Root.Get(k)
+
+		key, ok := yypLists[k]
+		is_list := func(v *YYValue) bool { return v == nil || v.Kind == YY_ARRAY }
+		if ok && o != nil && t != nil && is_list(b) && is_list(o) && is_list(t) {
+			merged, list_errs := mergeList(k, key, b, o, t)
+			errs = errs.Extend(list_errs)
+			out.Root.Fields = append(out.Root.Fields, YYField{k, merged})
+			continue
+		}
+
+		merged, ok := merge3(b, o, t, yyEqual)
+		if !ok {
+			errs = errs.Add(conflict(k, o, t))
+		}
+		if merged != nil {
+			out.Root.Fields = append(out.Root.Fields, YYField{k, merged.Clone()})
+		}
+	}
+
+	NormaliseYYP(&out)
+	return &out, errs
+}
@@ -0,0 +1,328 @@
+package typecheck
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// The signatures of GML's built-in functions, variables and constants
+// are kept in builtins.txt, one per line:
+//
+//	function draw_text(x: Real, y: Real, string: Any) -> Undefined
+//	function array_push(array: Array, ...values: Any) -> Undefined
+//	function string_pos_ext(substr: String, str: String, startpos?: Real) -> Real
+//	deprecated function array_length_1d(array: Array) -> Real
+//	variable room: Asset.GMRoom
+//	readonly instance id: Id.Instance
+//	constant c_white: Constant.Colour
+//
+// "variable" entries are global, "instance" ones belong to every
+// instance. Types are written the way ParseType reads them. Lines
+// starting with // are comments.
+
+//go:embed builtins.txt
+var builtinsData string
+
+type BUILTIN_KIND int
+
+const (
+	BK_FUNCTION BUILTIN_KIND = iota
+	BK_VARIABLE
+	BK_INSTANCE
+	BK_CONSTANT
+)
+
+// builtinKeywords are the words builtins.txt uses for each kind.
+var builtinKeywords = [...]string{
+	BK_FUNCTION: "function",
+	BK_VARIABLE: "variable",
+	BK_INSTANCE: "instance",
+	BK_CONSTANT: "constant",
+}
+
+type Builtin struct {
+	Name string
+	Kind BUILTIN_KIND
+	// Type is the function's type, with its signature, for functions.
+	Type       *Type
+	ReadOnly   bool
+	Deprecated bool
+	// line is where the entry was read from, text is how it was written
+	// there and comments are the comment and blank lines before it, so
+	// that Write can put them back.
+	line     int
+	text     string
+	comments []string
+}
+
+type Builtins map[string]*Builtin
+
+type BuiltinsError struct{ error }
+
+var defaultBuiltins = sync.OnceValue(func() Builtins {
+	b, err := ParseBuiltins(strings.NewReader(builtinsData))
+	if err != nil {
+		panic(err)
+	}
+	return b
+})
+
+// DefaultBuiltins returns the database shipped with gmtc. It is shared,
+// so callers must not modify it.
+func DefaultBuiltins() Builtins {
+	return defaultBuiltins()
+}
+
+// ParseBuiltins reads a database in the format of builtins.txt.
+func ParseBuiltins(r io.Reader) (Builtins, error) {
+	out := make(Builtins)
+	sc := bufio.NewScanner(r)
+	line_num := 0
+
+	comments := make([]string, 0)
+	for sc.Scan() {
+		line_num++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			comments = append(comments, line)
+			continue
+		}
+
+		b, err := parseBuiltin(line)
+		if err != nil {
+			return nil, BuiltinsError{fmt.Errorf("line %v: %w", line_num, err)}
+		}
+		if _, ok := out[b.Name]; ok {
+			return nil, BuiltinsError{fmt.Errorf("line %v: %v is declared twice", line_num, b.Name)}
+		}
+		b.line, b.text, b.comments = line_num, line, comments
+		comments = make([]string, 0)
+		out[b.Name] = b
+	}
+
+	return out, sc.Err()
+}
+
+func parseBuiltin(line string) (*Builtin, error) {
+	b := &Builtin{}
+
+	for {
+		word, rest, _ := strings.Cut(line, " ")
+		switch word {
+		case "deprecated":
+			b.Deprecated = true
+			line = strings.TrimSpace(rest)
+			continue
+		case "readonly":
+			b.ReadOnly = true
+			line = strings.TrimSpace(rest)
+			continue
+		}
+
+		kind := slices.Index(builtinKeywords[:], word)
+		if kind < 0 {
+			return nil, fmt.Errorf("expected function, variable, instance or constant, got %q", word)
+		}
+		b.Kind = BUILTIN_KIND(kind)
+		line = strings.TrimSpace(rest)
+		break
+	}
+
+	if b.Kind == BK_FUNCTION {
+		return b, b.parseFunction(line)
+	}
+
+	name, type_str, ok := strings.Cut(line, ":")
+	if !ok {
+		return nil, fmt.Errorf("%v has no type", line)
+	}
+	b.Name = strings.TrimSpace(name)
+	t, err := ParseType(strings.TrimSpace(type_str))
+	if err != nil {
+		return nil, err
+	}
+	b.Type = t
+	return b, nil
+}
+
+// splitTopLevel splits s at every sep that isn't inside brackets.
+func splitTopLevel(s string, sep byte) []string {
+	out := make([]string, 0)
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '<', '[':
+			depth++
+		case ')', '>', ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
+
+func (b *Builtin) parseFunction(line string) error {
+	open := strings.IndexByte(line, '(')
+	close := strings.LastIndexByte(line, ')')
+	if open < 0 || close < open {
+		return fmt.Errorf("%v has no parameter list", line)
+	}
+	b.Name = strings.TrimSpace(line[:open])
+
+	sig := &Signature{Return: Any}
+	if params := strings.TrimSpace(line[open+1 : close]); params != "" {
+		for _, param := range splitTopLevel(params, ',') {
+			name, type_str, ok := strings.Cut(param, ":")
+			if !ok {
+				return fmt.Errorf("%v: parameter %q has no type", b.Name, strings.TrimSpace(param))
+			}
+			t, err := ParseType(strings.TrimSpace(type_str))
+			if err != nil {
+				return fmt.Errorf("%v: %w", b.Name, err)
+			}
+
+			name = strings.TrimSpace(name)
+			if sig.Rest != nil {
+				return fmt.Errorf("%v: parameters after ...%v", b.Name, name)
+			}
+			if rest, ok := strings.CutPrefix(name, "..."); ok {
+				sig.Rest = t
+				sig.RestName = rest
+				continue
+			}
+			opt_name, optional := strings.CutSuffix(name, "?")
+			sig.Params = append(sig.Params, Param{Name: opt_name, Type: t, Optional: optional})
+		}
+	}
+
+	ret := strings.TrimSpace(line[close+1:])
+	if ret != "" {
+		ret, ok := strings.CutPrefix(ret, "->")
+		if !ok {
+			return fmt.Errorf("%v: expected -> before the return type", b.Name)
+		}
+		t, err := ParseType(strings.TrimSpace(ret))
+		if err != nil {
+			return fmt.Errorf("%v: %w", b.Name, err)
+		}
+		sig.Return = t
+	}
+
+	b.Type = FunctionOf(sig)
+	return nil
+}
+
+// Sig returns the signature of a function.
+func (b *Builtin) Sig() *Signature {
+	if b.Type == nil || b.Type.Func == nil {
+		return nil
+	}
+	return b.Type.Func
+}
+
+// String writes b the way it appears in builtins.txt.
+func (b *Builtin) String() string {
+	sb := strings.Builder{}
+	if b.Deprecated {
+		sb.WriteString("deprecated ")
+	}
+	if b.ReadOnly {
+		sb.WriteString("readonly ")
+	}
+	sb.WriteString(builtinKeywords[b.Kind] + " " + b.Name)
+
+	if b.Kind != BK_FUNCTION {
+		sb.WriteString(": " + b.Type.String())
+		return sb.String()
+	}
+
+	sig := b.Sig()
+	sb.WriteString("(")
+	for i, p := range sig.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(p.Name)
+		if p.Optional {
+			sb.WriteString("?")
+		}
+		sb.WriteString(": " + p.Type.String())
+	}
+	if sig.Rest != nil {
+		if len(sig.Params) > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("..." + sig.RestName + ": " + sig.Rest.String())
+	}
+	sb.WriteString(") -> " + sig.Return.String())
+	return sb.String()
+}
+
+// format is String, except that entries that haven't changed since they
+// were read are written as they were.
+func (b *Builtin) format() string {
+	out := b.String()
+	if b.text == "" {
+		return out
+	}
+	if read, err := parseBuiltin(b.text); err == nil && read.String() == out {
+		return b.text
+	}
+	return out
+}
+
+// Write writes the database in the format of builtins.txt. Entries that
+// were read from a file keep their place and the comments above them;
+// others are added at the end, grouped by kind and sorted by name.
+func (bs Builtins) Write(w io.Writer) error {
+	names := make([]string, 0, len(bs))
+	for name := range bs {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if bs[a].line != bs[b].line {
+			if bs[a].line == 0 || bs[b].line == 0 {
+				return bs[b].line - bs[a].line
+			}
+			return bs[a].line - bs[b].line
+		}
+		if bs[a].Kind != bs[b].Kind {
+			return int(bs[a].Kind) - int(bs[b].Kind)
+		}
+		return strings.Compare(a, b)
+	})
+
+	bw := bufio.NewWriter(w)
+	for i, name := range names {
+		b := bs[name]
+		if b.line == 0 && i > 0 && (bs[names[i-1]].line != 0 || bs[names[i-1]].Kind != b.Kind) {
+			bw.WriteString("\n")
+			if bs[names[i-1]].line != 0 {
+				bw.WriteString("// New entries, to be moved into their sections\n\n")
+			}
+		}
+		for _, c := range b.comments {
+			bw.WriteString(c + "\n")
+		}
+		bw.WriteString(b.format() + "\n")
+	}
+	return bw.Flush()
+}
+
+// Function returns the built-in function called name, or nil.
+func (bs Builtins) Function(name string) *Builtin {
+	if b, ok := bs[name]; ok && b.Kind == BK_FUNCTION {
+		return b
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package typecheck
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testBuiltins = `// Header
+
+// Drawing
+function draw_text(x: Real, y: Real, string: Any) -> Undefined
+deprecated function draw_old(x:Real) -> Undefined
+
+// Variables
+readonly instance id: Id.Instance
+variable room: Asset.GMRoom
+constant c_white: Constant.Colour
+`
+
+func parseTestBuiltins(t *testing.T, src string) Builtins {
+	t.Helper()
+	bs, err := ParseBuiltins(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bs
+}
+
+func TestDefaultBuiltins(t *testing.T) {
+	bs := DefaultBuiltins()
+	for _, name := range []string{"show_debug_message", "array_push", "physics_world_create", "gc_collect", "zip_unzip"} {
+		if bs.Function(name) == nil {
+			t.Errorf("no built-in function %v", name)
+		}
+	}
+	if bs.Function("room") != nil {
+		t.Errorf("room is a function")
+	}
+}
+
+func TestParseBuiltins(t *testing.T) {
+	bs := parseTestBuiltins(t, testBuiltins+`
+function array_push(array: Array, ...values: Any) -> Undefined
+function string_pos_ext(substr: String, str: String, startpos?: Real) -> Real
+`)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"draw_text", "function draw_text(x: Real, y: Real, string: Any) -> Undefined"},
+		{"draw_old", "deprecated function draw_old(x: Real) -> Undefined"},
+		{"id", "readonly instance id: Id.Instance"},
+		{"room", "variable room: Asset.GMRoom"},
+		{"c_white", "constant c_white: Constant.Colour"},
+		{"array_push", "function array_push(array: Array, ...values: Any) -> Undefined"},
+		{"string_pos_ext", "function string_pos_ext(substr: String, str: String, startpos?: Real) -> Real"},
+	}
+	for _, test := range tests {
+		b := bs[test.name]
+		if b == nil {
+			t.Errorf("%v wasn't read", test.name)
+		} else if got := b.String(); got != test.want {
+			t.Errorf("got %v, want %v", got, test.want)
+		}
+	}
+
+	sig := bs.Function("string_pos_ext").Sig()
+	if sig.MinArgs() != 2 || sig.MaxArgs() != 3 {
+		t.Errorf("string_pos_ext takes %v to %v arguments", sig.MinArgs(), sig.MaxArgs())
+	}
+	if sig := bs.Function("array_push").Sig(); sig.MaxArgs() != -1 || sig.RestName != "values" {
+		t.Errorf("array_push takes up to %v arguments, the rest called %q", sig.MaxArgs(), sig.RestName)
+	}
+}
+
+func TestParseBuiltinsErrors(t *testing.T) {
+	tests := []string{
+		"procedure f() -> Real",
+		"function f -> Real",
+		"function f(x) -> Real",
+		"function f(x: Realm) -> Real",
+		"function f(...xs: Any, y: Real) -> Real",
+		"function f() Real",
+		"variable x",
+		"constant c: Nope",
+		"variable x: Real\nvariable x: Real",
+	}
+	for _, src := range tests {
+		_, err := ParseBuiltins(strings.NewReader(src))
+		if err == nil {
+			t.Errorf("%q was read", src)
+		} else if !errors.As(err, &BuiltinsError{}) {
+			t.Errorf("%q: got %T, want a BuiltinsError", src, err)
+		}
+	}
+}
+
+func writeBuiltins(t *testing.T, bs Builtins) string {
+	t.Helper()
+	sb := strings.Builder{}
+	if err := bs.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestWriteBuiltins(t *testing.T) {
+	bs := parseTestBuiltins(t, testBuiltins)
+
+	// Entries are written as they were read, with their comments.
+	if got := writeBuiltins(t, bs); got != testBuiltins {
+		t.Errorf("got:\n%v\nwant:\n%v", got, testBuiltins)
+	}
+
+	// New entries go at the end under a heading, grouped by kind.
+	bs["zz_func"] = &Builtin{Name: "zz_func", Type: FunctionOf(&Signature{Return: Real})}
+	bs["aa_func"] = &Builtin{Name: "aa_func", Type: FunctionOf(&Signature{Return: Any})}
+	bs["c_new"] = &Builtin{Name: "c_new", Kind: BK_CONSTANT, Type: Real}
+	// Changed entries are rewritten where they are.
+	bs["room"].ReadOnly = true
+	want := strings.Replace(testBuiltins, "variable room", "readonly variable room", 1) + `
+// New entries, to be moved into their sections
+
+function aa_func() -> Any
+function zz_func() -> Real
+
+constant c_new: Real
+`
+	if got := writeBuiltins(t, bs); got != want {
+		t.Errorf("got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestImportFnames(t *testing.T) {
+	bs := parseTestBuiltins(t, testBuiltins)
+	out, err := ImportFnames(bs, strings.NewReader(`
+draw_text(x,y,string,[colour])
+array_push(array,value,...)
+alarm[0..11]@
+fps*
+c_white#
+draw_old(x)&
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		// Known entries keep their types and take the new parameters.
+		{"draw_text", "function draw_text(x: Real, y: Real, string: Any, colour?: Any) -> Undefined"},
+		{"draw_old", "deprecated function draw_old(x: Real) -> Undefined"},
+		{"c_white", "constant c_white: Constant.Colour"},
+		// New ones get Any.
+		{"array_push", "function array_push(array: Any, value: Any, ...args: Any) -> Any"},
+		{"alarm", "instance alarm: Array"},
+		{"fps", "readonly variable fps: Any"},
+		// Entries fnames doesn't have are kept.
+		{"room", "variable room: Asset.GMRoom"},
+	}
+	for _, test := range tests {
+		b := out[test.name]
+		if b == nil {
+			t.Errorf("%v is missing", test.name)
+		} else if got := b.String(); got != test.want {
+			t.Errorf("got %v, want %v", got, test.want)
+		}
+	}
+
+	if bs["draw_text"].Sig().MaxArgs() != 3 {
+		t.Errorf("ImportFnames changed the database it was given")
+	}
+
+	if _, err := ImportFnames(bs, strings.NewReader("f(x")); !errors.As(err, &BuiltinsError{}) {
+		t.Errorf("got %v for an unclosed parameter list, want a BuiltinsError", err)
+	}
+}
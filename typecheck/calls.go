@@ -0,0 +1,73 @@
+package typecheck
+
+import (
+	"fmt"
+	"gmtc/ast"
+	"gmtc/project"
+	"gmtc/utils"
+)
+
+type ArgCountError struct{ error }
+
+// CheckArgCounts reports calls that pass too many or too few arguments to
+// functions, constructors and methods declared in the project or its
+// extensions. Library resources aren't checked.
+func CheckArgCounts(p *project.Project, t *Types) utils.Errors {
+	out := make(utils.Errors, 0)
+	for _, scr := range checkedScripts(p) {
+		ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+			call, ok := n.(*ast.Call)
+			if !ok {
+				return true
+			}
+			if err := t.checkArgCount(call); err != nil {
+				out = out.AddPrefix(scr.ErrorPrefix(), err)
+			}
+			return true
+		})
+	}
+	return out
+}
+
+func (t *Types) checkArgCount(call *ast.Call) error {
+	fn := t.Of(call.Function)
+	if fn.Kind != TK_Function || fn.Func == nil || (t.decls[fn.Func] == nil && !t.externals[fn.Func]) {
+		return nil
+	}
+	sig := fn.Func
+
+	given, min_args, max_args := len(call.Params), sig.MinArgs(), sig.MaxArgs()
+	if given >= min_args && (max_args < 0 || given <= max_args) {
+		return nil
+	}
+
+	name := "function"
+	loc := call.Start()
+	if ident := identOf(call.Function); ident != nil {
+		name, loc = ident.Value, ident.Loc
+	} else if attr, ok := call.Function.(*ast.Attr); ok {
+		name, loc = attr.Name.Value, attr.Name.Loc
+	}
+
+	takes := plural(min_args, "argument")
+	switch {
+	case max_args < 0:
+		takes = "at least " + takes
+	case min_args != max_args:
+		takes = fmt.Sprintf("%v to %v", min_args, plural(max_args, "argument"))
+	}
+	return ArgCountError{fmt.Errorf(
+		"%v:%v: %v takes %v, but is given %v",
+		loc.Line+1, loc.Char+1, name, takes, given,
+	)}
+}
+
+func plural(n int, noun string) string {
+	if n == 0 {
+		return "no " + noun + "s"
+	}
+	if n == 1 {
+		return fmt.Sprintf("%v %v", n, noun)
+	}
+	return fmt.Sprintf("%v %vs", n, noun)
+}
@@ -0,0 +1,56 @@
+package typecheck
+
+import "testing"
+
+func TestCheckArgCounts(t *testing.T) {
+	p := testProject(t, "scr_a", `
+function scr_one(_a) {}
+function scr_some(_a, _b = 2) {}
+/// @param {Real} _a
+/// @param {String} [_name]
+function scr_named(_a, _name) {}
+function scr_any() { return argument_count; }
+function Pair(_a, _b) constructor {
+	function swap() {}
+	swap(1);
+}
+
+scr_one();
+scr_one(1);
+scr_one(1, 2);
+scr_some(1);
+scr_some(1, 2, 3);
+scr_named(1);
+scr_named();
+scr_any(1, 2, 3, 4);
+var _p = new Pair(1);
+show_debug_message(1, 2, 3);
+`)
+	// Parameters documented in brackets can be left out, and functions
+	// reading argument_count take anything. Built-in functions aren't
+	// checked here.
+	checkErrors(t, CheckArgCounts(p, inferTypes(p)),
+		"scripts/scr_a/scr_a.gml:10:2: swap takes no arguments, but is given 1",
+		"scripts/scr_a/scr_a.gml:13:1: scr_one takes 1 argument, but is given 0",
+		"scripts/scr_a/scr_a.gml:15:1: scr_one takes 1 argument, but is given 2",
+		"scripts/scr_a/scr_a.gml:17:1: scr_some takes 1 to 2 arguments, but is given 3",
+		"scripts/scr_a/scr_a.gml:19:1: scr_named takes 1 to 2 arguments, but is given 0",
+		"scripts/scr_a/scr_a.gml:21:14: Pair takes 2 arguments, but is given 1",
+	)
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "no arguments"},
+		{1, "1 argument"},
+		{2, "2 arguments"},
+	}
+	for _, test := range tests {
+		if got := plural(test.n, "argument"); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package typecheck
+
+import (
+	"fmt"
+	"gmtc/ast"
+	"gmtc/parser"
+	"gmtc/project"
+	"gmtc/utils"
+	"strings"
+)
+
+type UndefinedError struct{ error }
+
+// CheckUndefined reports reads of names that aren't declared anywhere, and of
+// instance variables that self never assigns, suggesting the closest
+// name that does exist. Library resources aren't checked.
+func CheckUndefined(p *project.Project, b *Bindings) utils.Errors {
+	out := make(utils.Errors, 0)
+	s := suggester{b: b, p: p}
+	for _, scr := range checkedScripts(p) {
+		out = out.Extend(s.script(scr))
+	}
+	return out
+}
+
+// checkedScripts returns the scripts of every resource that isn't part of
+// a library.
+func checkedScripts(p *project.Project) []*project.ResGMScript {
+	out := make([]*project.ResGMScript, 0)
+	for _, res := range p.Resources {
+		if p.IsLibrary(res) {
+			continue
+		}
+		if res, ok := res.(scriptsResource); ok {
+			out = append(out, res.Scripts()...)
+		}
+	}
+	return out
+}
+
+type suggester struct {
+	b *Bindings
+	p *project.Project
+
+	functions []string
+	variables []string
+}
+
+func (s *suggester) script(scr *project.ResGMScript) utils.Errors {
+	out := make(utils.Errors, 0)
+
+	calls := make(map[*parser.Token]bool)
+	ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+		if call, ok := n.(*ast.Call); ok {
+			if ident := identOf(call.Function); ident != nil {
+				calls[ident] = true
+			}
+		}
+		return true
+	})
+
+	locals := make([]string, 0)
+	for _, bd := range s.b.Scripts[scr] {
+		if bd.Symbol.Kind == SK_Local || bd.Symbol.Kind == SK_Argument {
+			locals = append(locals, bd.Symbol.Name)
+		}
+	}
+
+	for _, bd := range s.b.Scripts[scr] {
+		sym := bd.Symbol
+		if sym.Kind != SK_Unknown {
+			continue
+		}
+
+		var msg, prefix string
+		var candidates []string
+		name := sym.Name
+
+		switch {
+		case strings.HasPrefix(sym.Name, "global."):
+			msg = fmt.Sprintf("unknown global variable %v", sym.Name)
+			prefix, name = "global.", strings.TrimPrefix(sym.Name, "global.")
+			candidates = keys(s.b.GlobalVars)
+
+		case strings.Contains(sym.Name, "."):
+			enum, member, _ := strings.Cut(sym.Name, ".")
+			msg = fmt.Sprintf("enum %v has no member %v", enum, member)
+			prefix, name = enum+".", member
+			if sym := s.b.Globals[enum]; sym != nil {
+				candidates = keys(sym.Members)
+			}
+
+		case calls[bd.Ident]:
+			msg = fmt.Sprintf("unknown function %v", sym.Name)
+			candidates = append(s.functionNames(), locals...)
+
+		case sym.Owner != "":
+			msg = fmt.Sprintf("%v is never assigned in %v or its parents", sym.Name, sym.Owner)
+			candidates = s.memberNames(sym.Owner)
+
+		default:
+			msg = fmt.Sprintf("unknown variable %v", sym.Name)
+			candidates = append(s.variableNames(), locals...)
+		}
+
+		if match := closest(name, candidates); match != "" {
+			msg += fmt.Sprintf(", did you mean %v%v?", prefix, match)
+		}
+		loc := bd.Ident.Loc
+		out = out.AddPrefix(scr.ErrorPrefix(), UndefinedError{fmt.Errorf(
+			"%v:%v: %v", loc.Line+1, loc.Char+1, msg,
+		)})
+	}
+
+	return out
+}
+
+func (s *suggester) functionNames() []string {
+	if s.functions != nil {
+		return s.functions
+	}
+	for name, bi := range s.b.Builtins {
+		if bi.Kind == BK_FUNCTION {
+			s.functions = append(s.functions, name)
+		}
+	}
+	for name, sym := range s.b.Globals {
+		if sym.Kind == SK_Function {
+			s.functions = append(s.functions, name)
+		}
+	}
+	return s.functions
+}
+
+func (s *suggester) variableNames() []string {
+	if s.variables != nil {
+		return s.variables
+	}
+	for name, bi := range s.b.Builtins {
+		if bi.Kind != BK_FUNCTION {
+			s.variables = append(s.variables, name)
+		}
+	}
+	for name, sym := range s.b.Globals {
+		if sym.Kind != SK_Function {
+			s.variables = append(s.variables, name)
+		}
+	}
+	s.variables = append(s.variables, keys(s.b.instances)...)
+	return s.variables
+}
+
+// memberNames returns the instance variables of an object or constructor
+// and of everything it inherits from.
+func (s *suggester) memberNames(owner string) []string {
+	out := make([]string, 0)
+	for depth := 0; owner != "" && depth < 64; depth++ {
+		sym := s.b.Globals[owner]
+		if sym == nil {
+			break
+		}
+		out = append(out, keys(sym.Members)...)
+
+		owner = ""
+		if obj, ok := sym.Resource.(*project.ResGMObject); ok {
+			owner = obj.ParentObject
+		} else if fn, ok := sym.Decl.(*ast.FuncDecl); ok && fn.Parent != nil {
+			if ident := identOf(fn.Parent.Function); ident != nil {
+				owner = ident.Value
+			}
+		}
+	}
+	return out
+}
+
+// closest returns the candidate nearest to name, if it is near enough to
+// likely be a typo of it.
+func closest(name string, candidates []string) string {
+	limit := min(1+len(name)/4, 3, len(name)-1)
+	best, best_dist := "", limit+1
+
+	for _, c := range candidates {
+		if c == name || len(c)-len(name) > limit || len(name)-len(c) > limit {
+			continue
+		}
+		d := utils.EditDistance(name, c)
+		if d < best_dist || (d == best_dist && c < best) {
+			best, best_dist = c, d
+		}
+	}
+	return best
+}
+
+func keys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
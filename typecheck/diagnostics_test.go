@@ -0,0 +1,82 @@
+package typecheck
+
+import (
+	"gmtc/utils"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// errorLines returns the messages of errs in order, each as
+// "file:line:char: message".
+func errorLines(errs utils.Errors) []string {
+	out := make([]string, len(errs))
+	for i, err := range errs {
+		out[i] = strings.Replace(err.Error(), ": ", ":", 1)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func checkErrors(t *testing.T, errs utils.Errors, want ...string) {
+	t.Helper()
+	got := errorLines(errs)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("got errors:\n\t%v\nwant:\n\t%v", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
+
+func TestCheckUndefined(t *testing.T) {
+	p := testProject(t, "scr_a", `
+enum Dir { Left, Right }
+function scr_move(_speed) {
+	var _dist = _speed * 2;
+	global.total_dist = _dist;
+	scr_mvoe(_dsit);
+	show_debug_mesage(global.total_dst);
+	return Dir.Lfet + nothing_like_it;
+}
+`, "obj_a/Create_0", `
+stamina = 100;
+`, "obj_a/Step_0", `
+if (stamna <= 0) instance_destroy();
+`, "obj_b/Step_0", `
+show_debug_message(stamina);
+`)
+	b := Resolve(p, DefaultBuiltins())
+	checkErrors(t, CheckUndefined(p, b),
+		"scripts/scr_a/scr_a.gml:6:2: unknown function scr_mvoe, did you mean scr_move?",
+		"scripts/scr_a/scr_a.gml:6:11: unknown variable _dsit, did you mean _dist?",
+		"scripts/scr_a/scr_a.gml:7:2: unknown function show_debug_mesage, did you mean show_debug_message?",
+		"scripts/scr_a/scr_a.gml:7:27: unknown global variable global.total_dst, did you mean global.total_dist?",
+		"scripts/scr_a/scr_a.gml:8:13: enum Dir has no member Lfet, did you mean Dir.Left?",
+		"scripts/scr_a/scr_a.gml:8:20: unknown variable nothing_like_it",
+		"objects/obj_a/Step_0.gml:2:5: unknown variable stamna, did you mean stamina?",
+		// Another object assigns stamina, but obj_b doesn't.
+		"objects/obj_b/Step_0.gml:2:20: stamina is never assigned in obj_b or its parents",
+	)
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"health", "height", "hp", "x", "show_debug_message"}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"helth", "health"},
+		{"heigt", "height"},
+		{"health", ""},
+		{"hq", "hp"},
+		{"hpp", "hp"},
+		{"y", ""},
+		{"show_debug_mesage", "show_debug_message"},
+		{"show_dbg_msg", ""},
+		{"show_message", ""},
+	}
+	for _, test := range tests {
+		if got := closest(test.name, candidates); got != test.want {
+			t.Errorf("closest to %v is %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package typecheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GameMaker lists the runtime's functions, variables and constants in a
+// file called fnames, one per line. It has no types, but it is the
+// authoritative list of what exists:
+//
+//	draw_text(x,y,string)
+//	instance_create_layer(x,y,layer_id_or_name,obj,[var_struct])
+//	array_push(array,value,...)
+//	x@
+//	alarm[0..11]@
+//	fps*
+//	c_white#
+//	array_length_1d(array)&
+//
+// Names may be followed by flags: '@' for instance variables, '*' for
+// read-only ones, '#' for constants and '&' for deprecated entries.
+// Other flags ('%', '?', '!', '^', '$', '£') don't matter here.
+
+type fnamesEntry struct {
+	name     string
+	function bool
+	params   []Param
+	rest     bool
+	array    bool
+	flags    string
+}
+
+func parseFnamesLine(line string) (fnamesEntry, error) {
+	e := fnamesEntry{}
+
+	i := 0
+	for i < len(line) && isNameByte(line[i], i) {
+		i++
+	}
+	if i == 0 {
+		return e, fmt.Errorf("expected a name in %q", line)
+	}
+	e.name = line[:i]
+	rest := line[i:]
+
+	if strings.HasPrefix(rest, "(") {
+		close := strings.IndexByte(rest, ')')
+		if close < 0 {
+			return e, fmt.Errorf("%v: unclosed parameter list", e.name)
+		}
+		e.function = true
+		params := strings.TrimSpace(rest[1:close])
+		rest = rest[close+1:]
+
+		if params != "" {
+			for _, param := range strings.Split(params, ",") {
+				param = strings.TrimSpace(param)
+				if strings.HasSuffix(param, "...") {
+					e.rest = true
+					continue
+				}
+				opt_name, optional := strings.CutPrefix(param, "[")
+				if optional {
+					opt_name = strings.TrimSuffix(opt_name, "]")
+				}
+				e.params = append(e.params, Param{Name: opt_name, Type: Any, Optional: optional})
+			}
+		}
+	} else if strings.HasPrefix(rest, "[") {
+		close := strings.IndexByte(rest, ']')
+		if close < 0 {
+			return e, fmt.Errorf("%v: unclosed array bounds", e.name)
+		}
+		e.array = true
+		rest = rest[close+1:]
+	}
+
+	e.flags = strings.TrimSpace(rest)
+	return e, nil
+}
+
+func isNameByte(c byte, i int) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')
+}
+
+// ImportFnames updates a database from an fnames listing. Entries the
+// listing adds get Any wherever a type would go; entries already in the
+// database keep their types, but take their parameters, flags and kind
+// from the listing. Entries the listing doesn't have are kept as they
+// are.
+func ImportFnames(bs Builtins, r io.Reader) (Builtins, error) {
+	out := make(Builtins, len(bs))
+	for name, b := range bs {
+		out[name] = b
+	}
+
+	sc := bufio.NewScanner(r)
+	line_num := 0
+	for sc.Scan() {
+		line_num++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		e, err := parseFnamesLine(line)
+		if err != nil {
+			return nil, BuiltinsError{fmt.Errorf("line %v: %w", line_num, err)}
+		}
+		out[e.name] = e.merge(bs[e.name])
+	}
+
+	return out, sc.Err()
+}
+
+// merge builds the database entry for e, keeping what old knows about
+// types.
+func (e fnamesEntry) merge(old *Builtin) *Builtin {
+	b := &Builtin{
+		Name:       e.name,
+		ReadOnly:   strings.ContainsRune(e.flags, '*'),
+		Deprecated: strings.ContainsRune(e.flags, '&'),
+	}
+	if old != nil {
+		b.line, b.text, b.comments = old.line, old.text, old.comments
+	}
+
+	switch {
+	case e.function:
+		b.Kind = BK_FUNCTION
+	case strings.ContainsRune(e.flags, '#'):
+		b.Kind = BK_CONSTANT
+	case strings.ContainsRune(e.flags, '@'):
+		b.Kind = BK_INSTANCE
+	default:
+		b.Kind = BK_VARIABLE
+	}
+
+	if old != nil && old.Kind != b.Kind {
+		old = nil
+	}
+
+	if b.Kind != BK_FUNCTION {
+		switch {
+		case old != nil:
+			b.Type = old.Type
+		case e.array:
+			b.Type = ArrayOf(Any)
+		default:
+			b.Type = Any
+		}
+		return b
+	}
+
+	sig := &Signature{Return: Any}
+	var old_sig *Signature
+	if old != nil {
+		old_sig = old.Sig()
+		sig.Return = old_sig.Return
+	}
+
+	for i, p := range e.params {
+		if old_sig != nil {
+			if t := old_sig.ParamType(i); t != nil {
+				p.Type = t
+			}
+		}
+		sig.Params = append(sig.Params, p)
+	}
+	if e.rest {
+		sig.Rest, sig.RestName = Any, "args"
+		if old_sig != nil && old_sig.Rest != nil {
+			sig.Rest, sig.RestName = old_sig.Rest, old_sig.RestName
+		}
+	}
+
+	b.Type = FunctionOf(sig)
+	return b
+}
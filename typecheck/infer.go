@@ -0,0 +1,838 @@
+package typecheck
+
+import (
+	"fmt"
+	"gmtc/ast"
+	"gmtc/parser"
+	"gmtc/project"
+	"slices"
+	"strings"
+)
+
+// Types holds what is known about the types of a project's expressions,
+// locals and functions. Locals are followed through the assignments in
+// their function, so a local can have a different type at each use.
+type Types struct {
+	b *Bindings
+	// exprs are the types of expressions, at the point they are
+	// evaluated.
+	exprs map[ast.Node]*Type
+	// symbols are the types of locals, arguments, statics and global
+	// variables, over every value they are given.
+	symbols map[*Symbol]*Type
+	funcs   map[*ast.FuncDecl]*Signature
+	decls   map[*Signature]*ast.FuncDecl
+	// busy holds the functions being inferred, so that recursion ends.
+	busy map[*ast.FuncDecl]bool
+	// docs are the doc comments of functions, also kept by the signature
+	// they give, so that calls can be checked against them.
+	docs    map[*ast.FuncDecl]*Doc
+	docSigs map[*Signature]*Doc
+	// selfs are the types of self in constructors and the functions
+	// declared in them.
+	selfs map[*ast.FuncDecl]*Type
+	// members are the types of instance variables, over every value they
+	// are given.
+	members map[*Symbol]*Type
+	// added are the fields assigned to structs made by a constructor
+	// outside of it, keyed by the constructor's name.
+	added map[string]map[string]*Type
+	// externals are the signatures of extension functions, whose calls are
+	// checked against what the extension declares.
+	externals map[*Signature]bool
+}
+
+// Infer works out the types of everything in the project's code that can
+// be told: literals, operators, locals, arguments with default values and
+// calls to functions whose return type is known. Types given in doc
+// comments take precedence over inferred ones.
+func Infer(p *project.Project, b *Bindings) *Types {
+	t := &Types{
+		b:       b,
+		exprs:   make(map[ast.Node]*Type),
+		symbols: make(map[*Symbol]*Type),
+		funcs:   make(map[*ast.FuncDecl]*Signature),
+		decls:   make(map[*Signature]*ast.FuncDecl),
+		busy:    make(map[*ast.FuncDecl]bool),
+		docs:    make(map[*ast.FuncDecl]*Doc),
+		docSigs: make(map[*Signature]*Doc),
+		selfs:   make(map[*ast.FuncDecl]*Type),
+		members: make(map[*Symbol]*Type),
+		added:   make(map[string]map[string]*Type),
+
+		externals: make(map[*Signature]bool),
+	}
+
+	for name, fn := range p.ExtensionFunctions() {
+		sym := b.Globals[name]
+		if sym == nil || sym.Kind != SK_Function || sym.Resource == nil || sym.Resource.GetKind() != "GMExtension" {
+			continue
+		}
+		sig := extensionSignature(fn)
+		t.symbols[sym] = FunctionOf(sig)
+		t.externals[sig] = true
+	}
+
+	// The summary gives the types of library scripts' global variables.
+	for _, scr := range p.Scripts() {
+		if scr.Summary == nil {
+			continue
+		}
+		for _, vars := range []map[string]string{scr.Summary.Globalvars, scr.Summary.Globals} {
+			for name, s := range vars {
+				sym := b.GlobalVars[name]
+				if ty, err := ParseType(s); sym != nil && s != "" && err == nil {
+					t.symbols[sym] = Join(t.symbols[sym], ty)
+				}
+			}
+		}
+	}
+
+	// Object events have an instance of the object as self, and collision
+	// events one of the object collided with as other.
+	objects := make(map[string]bool)
+	selfs := make(map[*project.ResGMScript]*Type)
+	others := make(map[*project.ResGMScript]*Type)
+	creates := make(map[*project.ResGMScript]bool)
+	for _, res := range p.ResourcesOfKind("GMObject") {
+		objects[res.GetName()] = true
+		obj := res.(*project.ResGMObject)
+		for i := range obj.Events {
+			ev := &obj.Events[i]
+			selfs[&ev.ResGMScript] = InstanceOf(obj.GetName())
+			creates[&ev.ResGMScript] = ev.Type == project.GME_Create
+			if ev.Type == project.GME_Collision && ev.Collision != "" {
+				others[&ev.ResGMScript] = InstanceOf(ev.Collision)
+			}
+		}
+	}
+	for _, scr := range p.Scripts() {
+		lines := strings.Split(scr.Script, "\n")
+		ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+			if fn, ok := n.(*ast.FuncDecl); ok {
+				if doc := readDoc(lines, fn.Start().Line, objects); doc != nil {
+					t.docs[fn] = doc
+				}
+			}
+			return true
+		})
+		t.bindSelf(&scr.Ast, selfs[scr])
+	}
+
+	// Create events go first, so that the types of the variables they set
+	// are known in the other events.
+	scripts := p.Scripts()
+	slices.SortStableFunc(scripts, func(x, y *project.ResGMScript) int {
+		switch {
+		case creates[x] && !creates[y]:
+			return -1
+		case creates[y] && !creates[x]:
+			return 1
+		}
+		return 0
+	})
+	for _, scr := range scripts {
+		in := t.inferrer()
+		in.self, in.other = selfs[scr], others[scr]
+		in.block(scr.Ast.Children)
+	}
+	return t
+}
+
+// extensionSignature is the signature of an extension function. Its
+// arguments have no names, and are Real or String; Unknown ones are Any.
+func extensionSignature(fn project.ExtFunction) *Signature {
+	types := map[project.EXT_TYPE]*Type{project.ET_REAL: Real, project.ET_STRING: String}
+	sig := &Signature{Return: Any}
+	if ty := types[fn.Return]; ty != nil {
+		sig.Return = ty
+	}
+	if fn.Variadic {
+		sig.Rest, sig.RestName = Any, "argument"
+		return sig
+	}
+	for i, arg := range fn.Args {
+		ty := types[arg]
+		if ty == nil {
+			ty = Any
+		}
+		sig.Params = append(sig.Params, Param{Name: fmt.Sprintf("arg%v", i), Type: ty})
+	}
+	return sig
+}
+
+// bindSelf records the type of self in the functions below n: the struct
+// a constructor makes, in the constructor and in the functions declared in
+// it, which are bound to it, and self, in the functions declared in an
+// object's event.
+func (t *Types) bindSelf(n ast.Node, self *Type) {
+	for _, c := range ast.Children(n) {
+		switch c := c.(type) {
+		case *ast.FuncDecl:
+			inner := self
+			if c.IsConstructor && c.Name != nil {
+				inner = StructOf(c.Name.Value)
+			}
+			if inner != nil {
+				t.selfs[c] = inner
+			}
+			t.bindSelf(c, inner)
+		case *ast.Struct:
+			t.bindSelf(c, nil)
+		default:
+			t.bindSelf(c, self)
+		}
+	}
+}
+
+// Of returns the type of an expression, which is Any if nothing is known
+// about it.
+func (t *Types) Of(n ast.Node) *Type {
+	if ty, ok := t.exprs[n]; ok {
+		return ty
+	}
+	return Any
+}
+
+// Symbol returns the type of a symbol. For locals, instance variables
+// and global variables it covers every value they are given.
+func (t *Types) Symbol(sym *Symbol) *Type {
+	if ty, ok := t.symbols[sym]; ok {
+		return ty
+	}
+	switch sym.Kind {
+	case SK_Builtin:
+		if sym.Builtin.Type != nil {
+			return sym.Builtin.Type
+		}
+	case SK_Asset:
+		return AssetOf(sym.Resource.GetKind())
+	case SK_Enum:
+		if sym.Owner != "" {
+			return EnumOf(sym.Owner)
+		}
+	case SK_Function:
+		if fn, ok := sym.Decl.(*ast.FuncDecl); ok {
+			return FunctionOf(t.Func(fn))
+		}
+		return &Type{Kind: TK_Function}
+	case SK_Instance:
+		// Functions declared in events are methods of the instance.
+		if fn, ok := sym.Decl.(*ast.FuncDecl); ok {
+			return Join(t.members[sym], FunctionOf(t.Func(fn)))
+		}
+		if ty := t.members[sym]; ty != nil {
+			return ty
+		}
+	}
+	return Any
+}
+
+// Func returns the signature of a function declared in the project. It
+// has the types its doc comment gives; otherwise arguments with default
+// values have their type, and it returns what its return statements do.
+// Arguments with default values or documented in brackets are optional,
+// and functions that read argument or argument_count take any number of
+// arguments.
+func (t *Types) Func(fn *ast.FuncDecl) *Signature {
+	if sig, ok := t.funcs[fn]; ok {
+		return sig
+	}
+
+	doc := t.docs[fn]
+	if doc == nil {
+		doc = &Doc{}
+	}
+	sig := &Signature{Constructor: fn.IsConstructor, Return: doc.Return}
+	for i := range fn.Args {
+		ty := doc.Params[fn.Args[i].Name.Value]
+		if ty == nil {
+			ty = Any
+		}
+		sig.Params = append(sig.Params, Param{
+			Name:     fn.Args[i].Name.Value,
+			Type:     ty,
+			Optional: fn.Args[i].Default != nil || doc.Optional[fn.Args[i].Name.Value],
+		})
+	}
+	if fn.IsConstructor && fn.Name != nil {
+		sig.Return = StructOf(fn.Name.Value)
+	}
+	if readsArguments(fn) {
+		for i := range sig.Params {
+			sig.Params[i].Optional = true
+		}
+		sig.Rest, sig.RestName = Any, "argument"
+	}
+	if t.busy[fn] {
+		if sig.Return == nil {
+			sig.Return = Any
+		}
+		return sig
+	}
+	t.busy[fn] = true
+	defer delete(t.busy, fn)
+	if t.docs[fn] != nil {
+		t.docSigs[sig] = doc
+	}
+
+	in := t.inferrer()
+	in.self = doc.Self
+	if in.self == nil {
+		in.self = t.selfs[fn]
+	}
+	for i := range fn.Args {
+		arg := &fn.Args[i]
+		if arg.Default != nil {
+			ty := in.expr(arg.Default)
+			// A default of undefined only makes the argument optional.
+			if ty.Kind != TK_Undefined && doc.Params[arg.Name.Value] == nil {
+				sig.Params[i].Type = ty
+			}
+		}
+		if sym := t.b.Of(arg.Name); sym != nil {
+			in.set(sym, sig.Params[i].Type)
+		}
+	}
+	if fn.Parent != nil {
+		in.expr(fn.Parent)
+	}
+	in.block(fn.Body.Statements)
+
+	if sig.Return == nil {
+		sig.Return = in.returns
+		if sig.Return == nil || !in.returnsAlways {
+			sig.Return = Join(sig.Return, Undefined)
+		}
+	}
+	t.funcs[fn] = sig
+	t.decls[sig] = fn
+	return sig
+}
+
+// readsArguments reports whether a function reads its arguments through
+// argument, argumentN or argument_count, rather than only by name.
+func readsArguments(fn *ast.FuncDecl) bool {
+	found := false
+	ast.Inspect(&fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return false
+		case *ast.Simple:
+			if n.Type != ast.AST_IDENT {
+				break
+			}
+			name, ok := strings.CutPrefix(n.Value.Value, "argument")
+			if ok && (name == "" || name == "_count" || strings.Trim(name, "0123456789") == "") {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// Declaration is a name declared in a script and its type.
+type Declaration struct {
+	Ident *parser.Token
+	Type  *Type
+}
+
+// Declarations returns the types of the locals, statics, arguments and
+// named functions a script declares, in source order.
+func (t *Types) Declarations(scr *project.ResGMScript) []Declaration {
+	out := make([]Declaration, 0)
+	add := func(ident *parser.Token) {
+		if sym := t.b.Of(ident); sym != nil {
+			out = append(out, Declaration{ident, t.Symbol(sym)})
+		}
+	}
+
+	ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.VarDecl:
+			add(n.Name)
+		case *ast.FuncDecl:
+			if n.Name != nil {
+				out = append(out, Declaration{n.Name, FunctionOf(t.Func(n))})
+			}
+		case *ast.Arg:
+			add(n.Name)
+		}
+		return true
+	})
+	return out
+}
+
+// inferrer follows the types of the locals of one function, or of the
+// top level of a script.
+type inferrer struct {
+	t   *Types
+	env map[*Symbol]*Type
+	// self is the type of self, and other that of other, if they are
+	// known.
+	self  *Type
+	other *Type
+	// returns joins the types of the values returned so far, and
+	// returnsAlways is set once the code so far can't fall off its end.
+	returns       *Type
+	returnsAlways bool
+}
+
+func (t *Types) inferrer() *inferrer {
+	return &inferrer{t: t, env: make(map[*Symbol]*Type)}
+}
+
+func isLocal(sym *Symbol) bool {
+	return sym != nil && (sym.Kind == SK_Local || sym.Kind == SK_Argument || sym.Kind == SK_Static)
+}
+
+func isGlobal(sym *Symbol) bool {
+	return sym != nil && (sym.Kind == SK_Global || sym.Kind == SK_Globalvar)
+}
+
+// set gives a local a new type from here on.
+func (in *inferrer) set(sym *Symbol, ty *Type) {
+	in.env[sym] = ty
+	in.t.symbols[sym] = Join(in.t.symbols[sym], ty)
+}
+
+func (in *inferrer) fork() map[*Symbol]*Type {
+	env := make(map[*Symbol]*Type, len(in.env))
+	for sym, ty := range in.env {
+		env[sym] = ty
+	}
+	return env
+}
+
+// merge joins the locals of code paths that meet again.
+func merge(envs ...map[*Symbol]*Type) map[*Symbol]*Type {
+	out := make(map[*Symbol]*Type)
+	for _, env := range envs {
+		for sym, ty := range env {
+			out[sym] = Join(out[sym], ty)
+		}
+	}
+	return out
+}
+
+func (in *inferrer) block(stmts []ast.Statement) {
+	for _, stmt := range stmts {
+		in.stmt(stmt)
+	}
+}
+
+// branch runs the code in n on a copy of the locals, and returns them.
+func (in *inferrer) branch(n ast.Node) map[*Symbol]*Type {
+	outer := in.env
+	in.env = in.fork()
+	if n != nil {
+		in.stmt(n)
+	}
+	env := in.env
+	in.env = outer
+	return env
+}
+
+// loop runs a loop body twice, so that the second run sees the types its
+// locals get in the first.
+func (in *inferrer) loop(body ...ast.Node) {
+	for i := 0; i < 2; i++ {
+		outer := in.env
+		in.env = in.fork()
+		for _, n := range body {
+			if n != nil {
+				in.stmt(n)
+			}
+		}
+		in.env = merge(outer, in.env)
+	}
+}
+
+func (in *inferrer) stmt(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.Block:
+		in.block(n.Statements)
+
+	case *ast.VarDecl:
+		ty := Undefined
+		if n.Value != nil {
+			ty = in.expr(n.Value)
+		}
+		if sym := in.t.b.Of(n.Name); isLocal(sym) {
+			if n.Keyword() != "static" || in.env[sym] == nil {
+				in.set(sym, ty)
+			}
+		}
+
+	case *ast.IfStmt:
+		// The code after an if can only be skipped if every branch
+		// returns, including an else.
+		always := in.returnsAlways
+		all := n.Else != nil
+		branch := func(n ast.Node) map[*Symbol]*Type {
+			in.returnsAlways = always
+			env := in.branch(n)
+			all = all && in.returnsAlways
+			return env
+		}
+		in.expr(n.Condition)
+		envs := []map[*Symbol]*Type{branch(n.Body)}
+		for i := range n.Elseifs {
+			in.expr(n.Elseifs[i].Condition)
+			envs = append(envs, branch(n.Elseifs[i].Body))
+		}
+		envs = append(envs, branch(n.Else))
+		in.env = merge(envs...)
+		in.returnsAlways = always || all
+
+	case *ast.BlockStmt:
+		always := in.returnsAlways
+		switch n.Type {
+		case ast.AST_WHILE, ast.AST_REPEAT:
+			in.expr(n.Condition)
+			in.loop(n.Body)
+		case ast.AST_WITH:
+			outer, outer_other := in.self, in.other
+			in.self, in.other = in.withSelf(n.Condition), in.self
+			in.loop(n.Body)
+			in.self, in.other = outer, outer_other
+		case ast.AST_DOUNTIL:
+			in.loop(n.Body, n.Condition)
+		default:
+			in.expr(n.Condition)
+			in.stmt(n.Body)
+		}
+		in.returnsAlways = always
+
+	case *ast.ForLoop:
+		always := in.returnsAlways
+		in.stmt(&n.Assign)
+		in.loop(n.Cond, n.Body, n.Oper)
+		in.returnsAlways = always
+
+	case *ast.Switch:
+		always := in.returnsAlways
+		in.expr(n.Value)
+		envs := []map[*Symbol]*Type{in.fork()}
+		for i := range n.Cases {
+			c := &n.Cases[i]
+			if c.Value != nil {
+				in.expr(c.Value)
+			}
+			outer := in.env
+			in.env = in.fork()
+			in.block(c.Code)
+			envs = append(envs, in.env)
+			in.env = outer
+		}
+		in.env = merge(envs...)
+		in.returnsAlways = always
+
+	case *ast.TryCatch:
+		always := in.returnsAlways
+		envs := []map[*Symbol]*Type{in.branch(&n.TryBlock)}
+		if n.CatchBlock != nil {
+			if sym := in.t.b.Of(&n.CatchBlock.Ident); isLocal(sym) {
+				in.set(sym, Any)
+			}
+			envs = append(envs, in.branch(&n.CatchBlock.Block))
+		}
+		in.env = merge(envs...)
+		in.returnsAlways = always
+		if n.FinallyBlock != nil {
+			in.stmt(n.FinallyBlock)
+		}
+
+	case *ast.KwdStmt:
+		ty := Undefined
+		if n.Value != nil {
+			ty = in.expr(n.Value)
+		}
+		if n.Type == ast.AST_RETURN && !in.returnsAlways {
+			in.returns = Join(in.returns, ty)
+			in.returnsAlways = true
+		}
+
+	default:
+		in.expr(n)
+	}
+}
+
+func (in *inferrer) expr(n ast.Node) *Type {
+	ty := in.infer(n)
+	if ty == nil {
+		ty = Any
+	}
+	in.t.exprs[n] = ty
+	return ty
+}
+
+func (in *inferrer) infer(n ast.Node) *Type {
+	switch n := n.(type) {
+	case *ast.Simple:
+		switch n.Type {
+		case ast.AST_LITERAL_NUMBER:
+			return Real
+		case ast.AST_LITERAL_STRING:
+			return String
+		case ast.AST_LITERAL_BOOL:
+			return Bool
+		case ast.AST_IDENT:
+			return in.ident(n.Value)
+		}
+
+	case *ast.Array:
+		var elem *Type
+		for _, item := range n.Items {
+			elem = Join(elem, in.expr(item))
+		}
+		if elem == nil {
+			elem = Any
+		}
+		return ArrayOf(elem)
+
+	case *ast.Struct:
+		fields := make([]Field, 0, len(n.Fields))
+		for i := range n.Fields {
+			f := &n.Fields[i]
+			ty := Any
+			if f.Value != nil {
+				ty = in.expr(f.Value)
+			}
+			fields = append(fields, Field{Name: f.Name.Value, Type: ty})
+		}
+		return StructOf("", fields...)
+
+	case *ast.FuncDecl:
+		return FunctionOf(in.t.Func(n))
+
+	case *ast.Assign:
+		return in.assign(n)
+
+	case *ast.Binop:
+		return binopType(n.Op.Type, in.expr(n.Left), in.expr(n.Right))
+
+	case *ast.Unop:
+		ty := in.expr(n.Value)
+		switch n.Op.Type {
+		case parser.T_EXCLAM:
+			return Bool
+		case parser.T_INCREMENT, parser.T_DECREMENT:
+			if ident := identOf(n.Value); ident != nil {
+				if sym := in.t.b.Of(ident); isLocal(sym) {
+					in.set(sym, numericType(ty, ty))
+				}
+			}
+		}
+		return numericType(ty, ty)
+
+	case *ast.Ternary:
+		in.expr(n.Cond)
+		return Join(in.expr(n.OnTrue), in.expr(n.OnFalse))
+
+	case *ast.Call:
+		return in.call(n)
+
+	case *ast.Attr:
+		ty := in.expr(n.Value)
+		sym := in.t.b.Of(n.Name)
+		if sym != nil && sym.Kind == SK_Enum && sym.Owner != "" {
+			return EnumOf(sym.Owner)
+		}
+		if isGlobal(sym) {
+			return in.t.Symbol(sym)
+		}
+		return in.t.field(ty, n.Name.Value)
+
+	case *ast.KwdStmt:
+		if n.Type != ast.AST_NEW || n.Value == nil {
+			break
+		}
+		// new Foo(...) is a call to the constructor, but new Foo isn't.
+		ty := in.expr(n.Value)
+		if _, ok := n.Value.(*ast.Call); ok {
+			return ty
+		}
+		if ty.Kind == TK_Function && ty.Func != nil && ty.Func.Constructor {
+			return ty.Func.Return
+		}
+
+	case *ast.Access:
+		ty := in.expr(n.Value)
+		in.expr(n.Access)
+		if n.SecondAccess != nil {
+			in.expr(n.SecondAccess)
+		}
+		if n.Type.Type == parser.T_LSQUARE || n.Type.Type == parser.T_ACC_ARRAY {
+			return ty.Elem()
+		}
+
+	default:
+		for _, c := range ast.Children(n) {
+			in.expr(c)
+		}
+	}
+	return Any
+}
+
+func (in *inferrer) ident(ident *parser.Token) *Type {
+	sym := in.t.b.Of(ident)
+	if sym == nil {
+		return Any
+	}
+	if isLocal(sym) {
+		if ty, ok := in.env[sym]; ok {
+			return ty
+		}
+	}
+	if sym.Kind == SK_Builtin && sym.Name == "self" && in.self != nil {
+		return in.self
+	}
+	if sym.Kind == SK_Builtin && sym.Name == "other" && in.other != nil {
+		return in.other
+	}
+	return in.t.Symbol(sym)
+}
+
+// withSelf returns the type of self in the body of a with block: an
+// instance of the object it names, or the instance or struct it is given.
+func (in *inferrer) withSelf(target ast.Node) *Type {
+	ty := in.expr(target)
+	if ident := identOf(target); ident != nil {
+		if sym := in.t.b.Of(ident); sym != nil && sym.Kind == SK_Asset {
+			if obj, ok := sym.Resource.(*project.ResGMObject); ok {
+				return InstanceOf(obj.GetName())
+			}
+		}
+	}
+	if ty.Kind == TK_Struct || (ty.Kind == TK_Id && ty.Object != "") {
+		return ty
+	}
+	return nil
+}
+
+func (in *inferrer) assign(n *ast.Assign) *Type {
+	right := in.expr(n.Right)
+	ty := right
+	attr, is_attr := n.Left.(*ast.Attr)
+	if op, ok := compoundOps[n.Op.Type]; ok {
+		ty = binopType(op, in.expr(n.Left), right)
+	} else if is_attr {
+		recv := in.expr(attr.Value)
+		in.t.exprs[n.Left] = right
+		in.t.addShapeField(recv, attr.Name.Value, right)
+	} else if identOf(n.Left) != nil {
+		in.t.exprs[n.Left] = right
+	} else {
+		in.expr(n.Left)
+	}
+
+	var sym *Symbol
+	if ident := identOf(n.Left); ident != nil {
+		sym = in.t.b.Of(ident)
+	} else if is_attr {
+		sym = in.t.b.Of(attr.Name)
+		in.addField(attr, ty)
+	}
+	if isLocal(sym) {
+		in.set(sym, ty)
+	} else if sym != nil && sym.Kind == SK_Instance {
+		in.t.members[sym] = Join(in.t.members[sym], ty)
+	} else if isGlobal(sym) {
+		in.t.symbols[sym] = Join(in.t.symbols[sym], ty)
+	}
+	return ty
+}
+
+// addField follows the fields added to a struct literal held in a local.
+func (in *inferrer) addField(attr *ast.Attr, ty *Type) {
+	ident := identOf(attr.Value)
+	if ident == nil {
+		return
+	}
+	sym := in.t.b.Of(ident)
+	if !isLocal(sym) {
+		return
+	}
+	st := in.env[sym]
+	if st == nil || st.Kind != TK_Struct || st.Name != "" || st.Field(attr.Name.Value) != nil {
+		return
+	}
+	fields := append(slices.Clone(st.Fields), Field{Name: attr.Name.Value, Type: ty})
+	in.set(sym, StructOf("", fields...))
+}
+
+var compoundOps = map[parser.TOKEN_TYPE]parser.TOKEN_TYPE{
+	parser.T_ASSIGN_ADD:     parser.T_PLUS,
+	parser.T_ASSIGN_SUB:     parser.T_MINUS,
+	parser.T_ASSIGN_MUL:     parser.T_MUL,
+	parser.T_ASSIGN_DIV:     parser.T_DIV,
+	parser.T_ASSIGN_OR:      parser.T_BITOR,
+	parser.T_ASSIGN_AND:     parser.T_BITAND,
+	parser.T_ASSIGN_NULLISH: parser.T_NULLISH,
+}
+
+// call returns what a call returns, if the function called is known.
+func (in *inferrer) call(n *ast.Call) *Type {
+	fn := in.expr(n.Function)
+	for _, param := range n.Params {
+		in.expr(param)
+	}
+	if fn.Kind != TK_Function || fn.Func == nil || fn.Func.Return == nil {
+		return Any
+	}
+
+	// A method inherited from a parent constructor that returns self
+	// returns the struct it is called on.
+	ret := fn.Func.Return
+	if attr, ok := n.Function.(*ast.Attr); ok && ret.Kind == TK_Struct && ret.Name != "" {
+		recv := in.t.Of(attr.Value)
+		if recv.Kind == TK_Struct && in.t.inherits(recv.Name, ret.Name) {
+			return recv
+		}
+	}
+	return ret
+}
+
+// numericType is the type of arithmetic on a and b: Int64 if either is
+// one, else Real. Arithmetic on anything that isn't a number is an error
+// left for the checker, and gets Any.
+func numericType(a, b *Type) *Type {
+	if !a.numeric() || !b.numeric() {
+		return Any
+	}
+	if a.Kind == TK_Int64 || b.Kind == TK_Int64 {
+		return Int64
+	}
+	return Real
+}
+
+func binopType(op parser.TOKEN_TYPE, a, b *Type) *Type {
+	switch op {
+	case parser.T_LEQ, parser.T_GEQ, parser.T_EQ, parser.T_NEQ, parser.T_LESS, parser.T_MORE,
+		parser.T_AND, parser.T_OR:
+		return Bool
+	case parser.T_NULLISH:
+		return Join(withoutUndefined(a), b)
+	case parser.T_PLUS:
+		if a.Kind == TK_String && b.Kind == TK_String {
+			return String
+		}
+	}
+	return numericType(a, b)
+}
+
+// withoutUndefined is t without its Undefined alternative.
+func withoutUndefined(t *Type) *Type {
+	if t.Kind != TK_Union {
+		return t
+	}
+	members := make([]*Type, 0, len(t.Members))
+	for _, m := range t.Members {
+		if m.Kind != TK_Undefined {
+			members = append(members, m)
+		}
+	}
+	return UnionOf(members...)
+}
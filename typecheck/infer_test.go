@@ -0,0 +1,108 @@
+package typecheck
+
+import (
+	"gmtc/project"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// inferTypes resolves and infers the types of a test project.
+func inferTypes(p *project.Project) *Types {
+	return Infer(p, Resolve(p, DefaultBuiltins()))
+}
+
+func checkDeclarations(t *testing.T, types *Types, scr *project.ResGMScript, want ...string) {
+	t.Helper()
+	got := make([]string, 0)
+	for _, d := range types.Declarations(scr) {
+		got = append(got, d.Ident.Value+": "+d.Type.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("%v declares:\n\t%v\nwant:\n\t%v", scr.GMLPath, strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
+
+func TestInferLocals(t *testing.T) {
+	p := testProject(t, "scr_a", `
+function scr_a(_n = 0, _name = "") {
+	var _count = _n + 1;
+	var _label = _name + "!";
+	var _flag = _count > 2;
+	var _list = [1, 2, 3];
+	var _point = { x: 1, y: "two" };
+	var _maybe = undefined;
+	if (_flag) {
+		_maybe = 4;
+	}
+	var _len = string_length(_label);
+	var _first = _list[0];
+	return _point;
+}
+function scr_twice(_x) {
+	if (_x) return 1;
+	return "one";
+}
+var _result = scr_twice(true);
+`)
+	checkDeclarations(t, inferTypes(p), testScript(t, p, "scr_a"),
+		"scr_a: Function(_n?: Real, _name?: String) -> Struct{x: Real, y: String}",
+		"_n: Real",
+		"_name: String",
+		"_count: Real",
+		"_label: String",
+		"_flag: Bool",
+		"_list: Array<Real>",
+		"_point: Struct{x: Real, y: String}",
+		"_maybe: Undefined | Real",
+		"_len: Real",
+		"_first: Real",
+		"scr_twice: Function(_x: Any) -> Real | String",
+		"_x: Any",
+		"_result: Real | String",
+	)
+}
+
+func TestInferFlow(t *testing.T) {
+	// A local has the type of the last value it was given, and joins the
+	// values given to it in branches.
+	p := testProject(t, "scr_a", `
+var _v = 1;
+var _a = _v;
+_v = "s";
+var _b = _v;
+if (irandom(1)) {
+	_v = [];
+}
+var _c = _v;
+`)
+	checkDeclarations(t, inferTypes(p), testScript(t, p, "scr_a"),
+		"_v: Real | String | Array",
+		"_a: Real",
+		"_b: String",
+		"_c: Array | String",
+	)
+}
+
+func TestInferEventMethods(t *testing.T) {
+	// Functions declared in an event are methods of the instance, and
+	// calls to them, from any event of the object or its children, have
+	// their return type.
+	p := testProject(t, "obj_a/Create_0", `
+function describe(_a = 1, _b = "s") {
+	return _b;
+}
+`, "obj_a/Step_0", `
+var _own = describe();
+`, "obj_b/parent", "obj_a", "obj_b/Step_0", `
+var _inherited = describe(2);
+`)
+	types := inferTypes(p)
+	checkDeclarations(t, types, testScript(t, p, "obj_a/Create_0"),
+		"describe: Function(_a?: Real, _b?: String) -> String",
+		"_a: Real",
+		"_b: String",
+	)
+	checkDeclarations(t, types, testScript(t, p, "obj_a/Step_0"), "_own: String")
+	checkDeclarations(t, types, testScript(t, p, "obj_b/Step_0"), "_inherited: String")
+}
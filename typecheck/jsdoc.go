@@ -0,0 +1,250 @@
+package typecheck
+
+import (
+	"fmt"
+	"gmtc/ast"
+	"gmtc/project"
+	"gmtc/utils"
+	"strings"
+)
+
+type DocError struct{ error }
+type AnnotationError struct{ error }
+
+// Doc is what the /// comment above a function declares, the way Feather
+// reads them:
+//
+//	/// @param {Real} _x
+//	/// @param {String} [_name] Optional, so in brackets
+//	/// @return {Array<String>}
+//	/// @self Struct.Player
+//	/// @param {obj_player} _other An instance of obj_player
+//
+// Tags other than these are ignored, as are parameters without a type.
+type Doc struct {
+	Params map[string]*Type
+	// Optional are the parameters written in brackets.
+	Optional map[string]bool
+	Return   *Type
+	Self     *Type
+	// Errors are the malformed types in the comment.
+	Errors utils.Errors
+}
+
+// readDoc reads the /// comment that ends on the line before line, or
+// returns nil if there isn't one.
+func readDoc(lines []string, line int, objects map[string]bool) *Doc {
+	first := line
+	for first > 0 && strings.HasPrefix(strings.TrimSpace(lines[first-1]), "///") {
+		first--
+	}
+	if first == line {
+		return nil
+	}
+
+	doc := &Doc{Params: make(map[string]*Type), Optional: make(map[string]bool)}
+	for i := first; i < line; i++ {
+		doc.tag(lines[i], i, objects)
+	}
+	return doc
+}
+
+func (doc *Doc) tag(line string, line_num int, objects map[string]bool) {
+	text := strings.TrimPrefix(strings.TrimSpace(line), "///")
+	text = strings.TrimSpace(text)
+	tag, rest, _ := strings.Cut(text, " ")
+	rest = strings.TrimSpace(rest)
+
+	// col finds where the type starts, for errors.
+	col := strings.Index(line, rest) + 1
+	fail := func(err error) {
+		doc.Errors = doc.Errors.Add(DocError{fmt.Errorf("%v:%v: %v: %w", line_num+1, col, tag, err)})
+	}
+
+	switch strings.ToLower(tag) {
+	case "@param", "@arg", "@argument":
+		if !strings.HasPrefix(rest, "{") {
+			return
+		}
+		t, rest, err := braced(rest, objects)
+		if err != nil {
+			fail(err)
+			return
+		}
+		name, _, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		optional := strings.HasPrefix(name, "[")
+		name = strings.Trim(name, "[]")
+		name, _, _ = strings.Cut(name, "=")
+		if name == "" {
+			fail(fmt.Errorf("missing parameter name"))
+			return
+		}
+		doc.Params[name] = t
+		doc.Optional[name] = optional
+
+	case "@return", "@returns":
+		if !strings.HasPrefix(rest, "{") {
+			return
+		}
+		t, _, err := braced(rest, objects)
+		if err != nil {
+			fail(err)
+			return
+		}
+		doc.Return = t
+
+	case "@self", "@context":
+		if strings.HasPrefix(rest, "{") {
+			t, _, err := braced(rest, objects)
+			if err != nil {
+				fail(err)
+				return
+			}
+			doc.Self = t
+			return
+		}
+		name, _, _ := strings.Cut(rest, " ")
+		if objects[name] {
+			doc.Self = InstanceOf(name)
+			return
+		}
+		t, err := ParseType(name)
+		if err != nil {
+			fail(err)
+			return
+		}
+		doc.Self = t
+	}
+}
+
+// braced parses a type written in braces at the start of s, and returns
+// what follows it. An object's name stands for its instances.
+func braced(s string, objects map[string]bool) (*Type, string, error) {
+	if !strings.HasPrefix(s, "{") {
+		return nil, s, fmt.Errorf("expected a type in braces")
+	}
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return nil, s, fmt.Errorf("unclosed brace")
+	}
+	if name := strings.TrimSpace(s[1:end]); objects[name] {
+		return InstanceOf(name), s[end+1:], nil
+	}
+	t, err := ParseType(s[1:end])
+	return t, s[end+1:], err
+}
+
+// CheckAnnotations reports malformed doc comments, arguments that don't
+// match the types their parameters are documented with, or that
+// extensions declare, and returns that don't match @return. Library
+// resources aren't checked.
+func CheckAnnotations(p *project.Project, t *Types) utils.Errors {
+	out := make(utils.Errors, 0)
+	for _, scr := range checkedScripts(p) {
+		add := func(err error) {
+			out = out.AddPrefix(scr.ErrorPrefix(), err)
+		}
+
+		ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				doc := t.docs[n]
+				if doc == nil {
+					return true
+				}
+				for _, err := range doc.Errors {
+					add(err)
+				}
+				if doc.Return != nil {
+					t.checkReturns(n, doc.Return, add)
+				}
+
+			case *ast.Call:
+				t.checkArgs(n, add)
+			}
+			return true
+		})
+	}
+	return out
+}
+
+func (t *Types) checkReturns(fn *ast.FuncDecl, want *Type, add func(error)) {
+	ast.Inspect(&fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return false
+		case *ast.KwdStmt:
+			if n.Type != ast.AST_RETURN {
+				return true
+			}
+			got := Undefined
+			if n.Value != nil {
+				got = t.Of(n.Value)
+			}
+			if !got.SubtypeIn(want, t.descends) {
+				loc := n.Start()
+				add(AnnotationError{fmt.Errorf(
+					"%v:%v: returns %v, but is documented to return %v",
+					loc.Line+1, loc.Char+1, got, want,
+				)})
+			}
+		}
+		return true
+	})
+}
+
+func (t *Types) checkArgs(call *ast.Call, add func(error)) {
+	fn := t.Of(call.Function)
+	if fn.Kind != TK_Function || fn.Func == nil {
+		return
+	}
+	if t.externals[fn.Func] {
+		t.checkExternalArgs(call, fn.Func, add)
+		return
+	}
+	doc := t.docSigs[fn.Func]
+	if doc == nil {
+		return
+	}
+
+	for i, arg := range call.Params {
+		if i >= len(fn.Func.Params) {
+			break
+		}
+		param := fn.Func.Params[i]
+		want := doc.Params[param.Name]
+		if want == nil {
+			continue
+		}
+		if param.Optional {
+			want = Optional(want)
+		}
+		if got := t.Of(arg); !got.SubtypeIn(want, t.descends) {
+			loc := arg.Start()
+			add(AnnotationError{fmt.Errorf(
+				"%v:%v: %v is passed %v, but is documented as %v",
+				loc.Line+1, loc.Char+1, param.Name, got, want,
+			)})
+		}
+	}
+}
+
+func (t *Types) checkExternalArgs(call *ast.Call, sig *Signature, add func(error)) {
+	name := "function"
+	if ident := identOf(call.Function); ident != nil {
+		name = ident.Value
+	}
+	for i, arg := range call.Params {
+		want := sig.ParamType(i)
+		if want == nil || want == Any {
+			continue
+		}
+		if got := t.Of(arg); !got.SubtypeIn(want, t.descends) {
+			loc := arg.Start()
+			add(AnnotationError{fmt.Errorf(
+				"%v:%v: argument %v of %v is %v, but its extension declares %v",
+				loc.Line+1, loc.Char+1, i+1, name, got, want,
+			)})
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package typecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadDoc(t *testing.T) {
+	lines := strings.Split(`var x = 1;
+/// Moves towards a target.
+/// @param {Real} _speed How fast
+/// @param {String} [_name] Optional, so in brackets
+/// @param {obj_player} _other
+/// @param _untyped
+/// @arg {Array<Real>} [_path=[]]
+/// @returns {Bool}
+/// @self Struct.Mover
+function move(_speed, _name, _other, _untyped, _path) {}`, "\n")
+	objects := map[string]bool{"obj_player": true}
+
+	if readDoc(lines, 0, objects) != nil || readDoc(lines, 1, objects) != nil {
+		t.Errorf("read a doc comment where there is none")
+	}
+	doc := readDoc(lines, 9, objects)
+	if doc == nil {
+		t.Fatal("no doc comment")
+	}
+	if len(doc.Errors) > 0 {
+		t.Errorf("unexpected errors: %v", doc.Errors.Merge())
+	}
+
+	tests := []struct {
+		name     string
+		want     string
+		optional bool
+	}{
+		{"_speed", "Real", false},
+		{"_name", "String", true},
+		{"_other", "Id.Instance.obj_player", false},
+		{"_path", "Array<Real>", true},
+	}
+	for _, test := range tests {
+		ty := doc.Params[test.name]
+		if ty == nil {
+			t.Errorf("%v isn't documented", test.name)
+			continue
+		}
+		if ty.String() != test.want || doc.Optional[test.name] != test.optional {
+			t.Errorf("%v is %v (optional %v), want %v (optional %v)", test.name, ty, doc.Optional[test.name], test.want, test.optional)
+		}
+	}
+	if doc.Params["_untyped"] != nil {
+		t.Errorf("_untyped has a type")
+	}
+	if doc.Return.String() != "Bool" || doc.Self.String() != "Struct.Mover" {
+		t.Errorf("returns %v with self %v", doc.Return, doc.Self)
+	}
+}
+
+func TestReadDocErrors(t *testing.T) {
+	lines := strings.Split(`/// @param {Reel} _x
+/// @param {Real _y
+/// @param {Real}
+/// @return {Array<}
+function f(_x, _y) {}`, "\n")
+	doc := readDoc(lines, 4, nil)
+	checkErrors(t, doc.Errors,
+		`1:12:@param: type "Reel": unknown type Reel`,
+		"2:12:@param: unclosed brace",
+		"3:12:@param: missing parameter name",
+		`4:13:@return: type "Array<": missing type`,
+	)
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	p := testProject(t, "scr_a", `
+/// @param {Real} _speed
+/// @param {String} [_name]
+/// @return {String}
+function scr_move(_speed, _name) {
+	if (_speed > 10) return 10;
+	return _name;
+}
+
+/// @param {Bool} _on
+function scr_toggle(_on) {}
+
+/// @param {obj_enemy} _enemy
+function scr_hit(_enemy) {}
+
+scr_move(1);
+scr_move(1, undefined);
+scr_move(string(1), 2);
+scr_toggle(true);
+scr_toggle(1);
+scr_toggle(Dir.Left);
+scr_toggle(string(1));
+`, "scr_b", `
+enum Dir { Left }
+`, "obj_enemy/Create_0", `
+scr_hit(id);
+`, "obj_boss/parent", "obj_enemy", "obj_boss/Step_0", `
+scr_hit(self);
+`, "obj_wall/Step_0", `
+scr_hit(self);
+`)
+	// Numbers stand in for booleans, parameters in brackets can be left
+	// undefined and instances of obj_boss are instances of obj_enemy, so
+	// none of those calls are reported.
+	checkErrors(t, CheckAnnotations(p, inferTypes(p)),
+		"scripts/scr_a/scr_a.gml:6:19: returns Real, but is documented to return String",
+		"scripts/scr_a/scr_a.gml:18:10: _speed is passed String, but is documented as Real",
+		"scripts/scr_a/scr_a.gml:18:21: _name is passed Real, but is documented as String | Undefined",
+		"scripts/scr_a/scr_a.gml:22:12: _on is passed String, but is documented as Bool",
+		"objects/obj_wall/Step_0.gml:2:9: _enemy is passed Id.Instance.obj_wall, but is documented as Id.Instance.obj_enemy",
+	)
+}
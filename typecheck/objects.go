@@ -0,0 +1,372 @@
+package typecheck
+
+import (
+	"fmt"
+	"gmtc/ast"
+	"gmtc/parser"
+	"gmtc/project"
+	"gmtc/utils"
+	"path"
+	"slices"
+	"strings"
+)
+
+type InstanceVarError struct{ error }
+
+// instanceField returns the type of an instance variable of an object,
+// which is set in its events or its parents', or is built in. It is nil
+// if the object isn't known to have it.
+func (t *Types) instanceField(object, name string) *Type {
+	for depth := 0; object != "" && depth < 64; depth++ {
+		sym := t.b.Globals[object]
+		if sym == nil {
+			break
+		}
+		if m := sym.Members[name]; m != nil {
+			return t.Symbol(m)
+		}
+		obj, ok := sym.Resource.(*project.ResGMObject)
+		if !ok {
+			break
+		}
+		object = obj.ParentObject
+	}
+	if bi := t.b.Builtins[name]; bi != nil && bi.Kind == BK_INSTANCE {
+		return bi.Type
+	}
+	return nil
+}
+
+// descends reports whether an object is a descendant of another.
+func (t *Types) descends(object, ancestor string) bool {
+	for depth := 0; object != "" && depth < 64; depth++ {
+		sym := t.b.Globals[object]
+		if sym == nil {
+			return false
+		}
+		obj, ok := sym.Resource.(*project.ResGMObject)
+		if !ok {
+			return false
+		}
+		object = obj.ParentObject
+		if object == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// objectChain returns an object and its ancestors, nearest first.
+func objectChain(p *project.Project, obj *project.ResGMObject) []*project.ResGMObject {
+	out := []*project.ResGMObject{obj}
+	for len(out) < 64 && obj.ParentObject != "" {
+		parent, ok := p.ResourceOfKind("GMObject", obj.ParentObject).(*project.ResGMObject)
+		if !ok || slices.Contains(out, parent) {
+			break
+		}
+		out = append(out, parent)
+		obj = parent
+	}
+	return out
+}
+
+type eventKey struct {
+	Type      project.GM_EVENT
+	Num       int
+	Collision string
+}
+
+// objectEvents returns the events that run for instances of the first
+// object of a chain: its own, and those of its ancestors that it doesn't
+// override or that call event_inherited() from the overriding event.
+func objectEvents(chain []*project.ResGMObject) []*project.ResGMEvent {
+	out := make([]*project.ResGMEvent, 0)
+	seen := make(map[eventKey]bool)
+	inherit := make(map[eventKey]bool)
+	for _, obj := range chain {
+		for i := range obj.Events {
+			ev := &obj.Events[i]
+			key := eventKey{ev.Type, ev.Num, ev.Collision}
+			if seen[key] && !inherit[key] {
+				continue
+			}
+			out = append(out, ev)
+			seen[key] = true
+			inherit[key] = callsInherited(ev)
+		}
+	}
+	return out
+}
+
+func callsInherited(ev *project.ResGMEvent) bool {
+	found := false
+	ast.Inspect(&ev.Ast, func(n ast.Node) bool {
+		if call, ok := n.(*ast.Call); ok && isIdent(call.Function, "event_inherited") {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// Step and Draw events run in this order every frame, after the Create
+// event. The Draw events are keyed by their number.
+var drawOrder = []int{76, 72, 0, 73, 77, 74, 64, 75}
+
+// frameOrder ranks Step and Draw events by when they run in a frame, and
+// is -1 for other events, which don't run at a fixed point.
+func frameOrder(ev *project.ResGMEvent) int {
+	switch ev.Type {
+	case project.GME_Step:
+		switch ev.Num {
+		case 1:
+			return 0
+		case 0:
+			return 1
+		case 2:
+			return 2
+		}
+	case project.GME_Draw:
+		if i := slices.Index(drawOrder, ev.Num); i >= 0 {
+			return 3 + i
+		}
+	}
+	return -1
+}
+
+func eventName(ev *project.ResGMEvent) string {
+	return strings.TrimSuffix(path.Base(ev.GMLPath), ".gml")
+}
+
+// varAccess is a read or a plain assignment of an instance variable.
+type varAccess struct {
+	Event *project.ResGMEvent
+	Ident *parser.Token
+	Name  string
+	Write bool
+	// Index is where the access takes effect: the end of the statement,
+	// for assignments.
+	Index int
+}
+
+// eventAccesses lists the accesses to the given instance variables that an
+// event makes itself, in order. Those in functions it declares and in
+// with blocks happen at other times, or to other instances, so they are
+// left out.
+func (b *Bindings) eventAccesses(ev *project.ResGMEvent, vars map[*Symbol]bool) []varAccess {
+	out := make([]varAccess, 0)
+	add := func(ident *parser.Token, write bool, index int) {
+		if sym := b.Of(ident); sym != nil && vars[sym] {
+			out = append(out, varAccess{ev, ident, sym.Name, write, index})
+		}
+	}
+
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			return false
+
+		case *ast.BlockStmt:
+			if n.Type == ast.AST_WITH {
+				ast.Inspect(n.Condition, visit)
+				return false
+			}
+
+		case *ast.Assign:
+			if n.Op.Type != parser.T_ASSIGN {
+				break
+			}
+			if ident := identOf(n.Left); ident != nil {
+				ast.Inspect(n.Right, visit)
+				add(ident, true, n.End().Index)
+				return false
+			}
+			if attr, ok := n.Left.(*ast.Attr); ok && isIdent(attr.Value, "self") {
+				ast.Inspect(n.Right, visit)
+				add(attr.Name, true, n.End().Index)
+				return false
+			}
+
+		case *ast.Attr:
+			if isIdent(n.Value, "self") {
+				add(n.Name, false, n.Name.Loc.Index)
+			} else {
+				ast.Inspect(n.Value, visit)
+			}
+			return false
+
+		case *ast.Simple:
+			if n.Type == ast.AST_IDENT {
+				add(n.Value, false, n.Value.Loc.Index)
+			}
+		}
+		return true
+	}
+	ast.Inspect(&ev.Ast, visit)
+
+	slices.SortStableFunc(out, func(x, y varAccess) int { return x.Index - y.Index })
+	return out
+}
+
+// CheckInstanceVars reports instance variables that objects' Step and
+// Draw events read before any event has assigned them, and ones that are
+// only assigned outside the Create event, so that reading them in
+// another event can fail. Variable Definitions and the variables parents
+// set count as assigned in Create, and variables that other scripts set
+// on the instance, such as through with, are left alone. Library
+// resources aren't checked.
+func CheckInstanceVars(p *project.Project, b *Bindings) utils.Errors {
+	// writes holds every plain assignment of an instance variable, to tell
+	// the ones objects' events make from those made elsewhere.
+	writes := make(map[*Symbol][]*parser.Token)
+	for _, scr := range p.Scripts() {
+		ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+			assign, ok := n.(*ast.Assign)
+			if !ok || assign.Op.Type != parser.T_ASSIGN {
+				return true
+			}
+			ident := identOf(assign.Left)
+			if attr, ok := assign.Left.(*ast.Attr); ok {
+				ident = attr.Name
+			}
+			if sym := b.Of(ident); ident != nil && sym != nil && sym.Kind == SK_Instance {
+				writes[sym] = append(writes[sym], ident)
+			}
+			return true
+		})
+	}
+
+	out := make(utils.Errors, 0)
+	for _, res := range p.ResourcesOfKind("GMObject") {
+		if !p.IsLibrary(res) {
+			out = out.Extend(checkObjectVars(p, b, res.(*project.ResGMObject), writes))
+		}
+	}
+	return out
+}
+
+func checkObjectVars(p *project.Project, b *Bindings, obj *project.ResGMObject, writes map[*Symbol][]*parser.Token) utils.Errors {
+	chain := objectChain(p, obj)
+
+	vars := make(map[*Symbol]bool)
+	initial := make(map[string]bool)
+	for _, o := range chain {
+		if sym := b.Globals[o.GetName()]; sym != nil && sym.Resource == o {
+			for _, m := range sym.Members {
+				vars[m] = true
+			}
+		}
+		for _, name := range o.Properties {
+			initial[name] = true
+		}
+	}
+
+	events := objectEvents(chain)
+	accesses := make(map[*project.ResGMEvent][]varAccess)
+	in_events := make(map[*parser.Token]bool)
+	assigned_in := make(map[string][]*project.ResGMEvent)
+	for _, ev := range events {
+		accesses[ev] = b.eventAccesses(ev, vars)
+		for _, a := range accesses[ev] {
+			in_events[a.Ident] = true
+			if !a.Write {
+				continue
+			}
+			if ev.Type == project.GME_Create {
+				initial[a.Name] = true
+			}
+			if !slices.Contains(assigned_in[a.Name], ev) {
+				assigned_in[a.Name] = append(assigned_in[a.Name], ev)
+			}
+		}
+	}
+
+	// Variables that other code sets on the instance can't be followed.
+	elsewhere := make(map[string]bool)
+	for sym := range vars {
+		for _, ident := range writes[sym] {
+			if !in_events[ident] {
+				elsewhere[sym.Name] = true
+			}
+		}
+	}
+
+	out := make(utils.Errors, 0)
+	add := func(ev *project.ResGMEvent, ident *parser.Token, msg string) {
+		out = out.AddPrefix(ev.ErrorPrefix(), InstanceVarError{fmt.Errorf(
+			"%v:%v: %v", ident.Loc.Line+1, ident.Loc.Char+1, msg,
+		)})
+	}
+
+	reported := make(map[string]bool)
+	for i := range obj.Events {
+		ev := &obj.Events[i]
+		order := frameOrder(ev)
+		if order < 0 {
+			continue
+		}
+		set := make(map[string]bool)
+		for _, a := range accesses[ev] {
+			name := a.Name
+			if a.Write {
+				set[name] = true
+			}
+			if a.Write || set[name] || initial[name] || elsewhere[name] || reported[name] {
+				continue
+			}
+			if len(assigned_in[name]) == 0 || setsEarlier(assigned_in[name], ev, order) {
+				continue
+			}
+
+			others := make([]string, 0)
+			later := false
+			for _, by := range assigned_in[name] {
+				if by == ev {
+					later = true
+				} else {
+					others = append(others, eventName(by))
+				}
+			}
+			where := "in " + strings.Join(others, ", ")
+			if later && len(others) > 0 {
+				where += " and later in this event"
+			} else if later {
+				where = "later in this event"
+			}
+			add(ev, a.Ident, fmt.Sprintf("%v is read before it is assigned; it is only assigned %v", name, where))
+			reported[name] = true
+		}
+	}
+
+	for i := range obj.Events {
+		ev := &obj.Events[i]
+		for _, a := range accesses[ev] {
+			name := a.Name
+			if !a.Write || initial[name] || elsewhere[name] || reported[name] {
+				continue
+			}
+			names := make([]string, len(assigned_in[name]))
+			for i, by := range assigned_in[name] {
+				names[i] = eventName(by)
+			}
+			add(ev, a.Ident, fmt.Sprintf("%v is only assigned outside Create, in %v", name, strings.Join(names, ", ")))
+			reported[name] = true
+		}
+	}
+	return out
+}
+
+// setsEarlier reports whether any of the events run before ev in a frame,
+// or at times that aren't known.
+func setsEarlier(events []*project.ResGMEvent, ev *project.ResGMEvent, order int) bool {
+	for _, by := range events {
+		if by == ev {
+			continue
+		}
+		if o := frameOrder(by); o < 0 || o < order {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package typecheck
+
+import "testing"
+
+func TestCheckInstanceVars(t *testing.T) {
+	p := testProject(t, "obj_base/Create_0", `
+hp = 3;
+`, "obj_a/parent", "obj_base", "obj_a/Create_0", `
+event_inherited();
+speed_x = 0;
+`, "obj_a/Step_0", `
+x += speed_x + hp;
+if (target != noone) {
+	x = target.x;
+}
+y += fall;
+fall = 1;
+if (flash) {
+	flash = false;
+}
+show_debug_message(tag);
+`, "obj_a/Draw_0", `
+target = instance_nearest(x, y, obj_base);
+draw_self();
+`, "obj_a/Alarm_0", `
+flash = true;
+`, "obj_b/Create_0", `
+with (obj_a) {
+	tag = "set by obj_b";
+}
+`)
+	// Variables set in Create, by a parent or by other code on the
+	// instance are fine to read anywhere.
+	checkErrors(t, CheckInstanceVars(p, Resolve(p, DefaultBuiltins())),
+		"objects/obj_a/Step_0.gml:3:5: target is read before it is assigned; it is only assigned in Draw_0",
+		"objects/obj_a/Step_0.gml:6:6: fall is read before it is assigned; it is only assigned later in this event",
+		"objects/obj_a/Step_0.gml:9:2: flash is only assigned outside Create, in Step_0, Alarm_0",
+	)
+}
+
+func TestInferSelf(t *testing.T) {
+	p := testProject(t, "obj_a/Create_0", `
+count = 1;
+var _me = self;
+var _count = _me.count;
+var _x = _me.x;
+`, "obj_b/parent", "obj_a", "obj_b/Step_0", `
+var _count = count;
+`)
+	types := inferTypes(p)
+	checkDeclarations(t, types, testScript(t, p, "obj_a/Create_0"),
+		"_me: Id.Instance.obj_a",
+		"_count: Real",
+		"_x: Real",
+	)
+	checkDeclarations(t, types, testScript(t, p, "obj_b/Step_0"), "_count: Real")
+}
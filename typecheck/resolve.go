@@ -0,0 +1,804 @@
+package typecheck
+
+import (
+	"gmtc/ast"
+	"gmtc/parser"
+	"gmtc/project"
+	"slices"
+)
+
+type SYMBOL_KIND int
+
+//go:generate stringer -type=SYMBOL_KIND -trimprefix=SK_
+const (
+	SK_Unknown SYMBOL_KIND = iota
+	SK_Local
+	SK_Argument
+	SK_Instance
+	SK_Static
+	SK_Global
+	SK_Globalvar
+	SK_Function
+	SK_Enum
+	SK_Macro
+	SK_Asset
+	SK_Builtin
+)
+
+// Symbol is anything an identifier can refer to.
+type Symbol struct {
+	Name string
+	Kind SYMBOL_KIND
+	// Owner is the object or constructor an instance variable or static
+	// belongs to, the function a local belongs to, or the enum a member
+	// belongs to. It is empty when that isn't known.
+	Owner string
+	// Script and Decl locate the declaration of symbols declared in code.
+	Script *project.ResGMScript
+	Decl   ast.Node
+	// Resource is the asset itself, or the resource that declares a
+	// function or macro.
+	Resource project.Resource
+	Builtin  *Builtin
+	// Members are the members of an enum, or the instance variables of an
+	// object or constructor.
+	Members map[string]*Symbol
+}
+
+// Binding is an identifier and the symbol it refers to.
+type Binding struct {
+	Ident  *parser.Token
+	Symbol *Symbol
+}
+
+type Bindings struct {
+	Builtins Builtins
+	// Globals are the names that mean the same thing everywhere: script
+	// functions, globalvars, enums, macros and assets. Functions and
+	// constants declared by extensions count as script functions and
+	// macros.
+	Globals map[string]*Symbol
+	// GlobalVars are the variables used through global.
+	GlobalVars map[string]*Symbol
+	// Scripts holds each script's bindings in source order. Identifiers
+	// that came from a macro expansion are left out, as they aren't in
+	// the script's source.
+	Scripts map[*project.ResGMScript][]Binding
+
+	byToken  map[*parser.Token]*Symbol
+	builtins map[string]*Symbol
+	unknown  map[string]*Symbol
+	// instances holds every instance variable assigned anywhere, for
+	// code that doesn't know what self is.
+	instances map[string]*Symbol
+}
+
+// Of returns what an identifier in a script's AST refers to.
+func (b *Bindings) Of(ident *parser.Token) *Symbol {
+	return b.byToken[ident]
+}
+
+// At returns the binding of the identifier at line and char of a script,
+// both counted from 0, or nil if there is none.
+func (b *Bindings) At(script *project.ResGMScript, line, char int) *Binding {
+	bs := b.Scripts[script]
+	for i := range bs {
+		loc := bs[i].Ident.Loc
+		if loc.Line == line && char >= loc.Char && char < loc.Char+len(bs[i].Ident.Value) {
+			return &bs[i]
+		}
+	}
+	return nil
+}
+
+// selfScope is what self refers to in some stretch of code.
+type selfScope struct {
+	owner string
+	// members is nil when self isn't known.
+	members map[string]*Symbol
+	// isStruct is set for constructors and struct literals, which don't
+	// have the built-in instance variables.
+	isStruct bool
+	// parent is the scope of the parent object or constructor, whose
+	// members self also has.
+	parent *selfScope
+	linked bool
+}
+
+type funcScope struct {
+	decl    *ast.FuncDecl
+	name    string
+	locals  map[string]*Symbol
+	statics map[string]*Symbol
+	// top is set for the top level of a script or event.
+	top bool
+}
+
+type resolver struct {
+	b *Bindings
+	p *project.Project
+	// collect is set on the first pass over the code, which only records
+	// what instance variables each self has.
+	collect bool
+
+	script      *project.ResGMScript
+	globalFuncs bool
+	fn          *funcScope
+	self        *selfScope
+	// other is the instance that ran the with block self is in, or the one
+	// a collision event collides with.
+	other       *selfScope
+	unknownSelf *selfScope
+	// scopes are keyed by the object, constructor or struct literal they
+	// belong to, so that both passes see the same ones.
+	scopes map[any]*selfScope
+}
+
+type scriptsResource interface {
+	Scripts() []*project.ResGMScript
+}
+
+// Resolve works out what every identifier in the project refers to,
+// following GML's rules: functions declared at the top of a script are
+// global, other named functions are methods of self, and locals aren't
+// visible from nested functions. `with` makes self the instances it
+// iterates over and other the instance that ran it, other is the object
+// collided with in collision events, and functions in a struct literal
+// are methods of the struct.
+//
+// The project must have been parsed. The ASTs that the parse cache left
+// out are built first; library scripts that stand in for the summary
+// only contribute the functions, enums and global variables it lists.
+func Resolve(p *project.Project, bs Builtins) *Bindings {
+	p.ParseASTs()
+	b := &Bindings{
+		Builtins:   bs,
+		Globals:    make(map[string]*Symbol),
+		GlobalVars: make(map[string]*Symbol),
+		Scripts:    make(map[*project.ResGMScript][]Binding),
+		byToken:    make(map[*parser.Token]*Symbol),
+		builtins:   make(map[string]*Symbol),
+		unknown:    make(map[string]*Symbol),
+		instances:  make(map[string]*Symbol),
+	}
+	r := &resolver{
+		b:           b,
+		p:           p,
+		unknownSelf: &selfScope{},
+		scopes:      make(map[any]*selfScope),
+	}
+
+	r.declareGlobals()
+	r.collect = true
+	r.walkProject()
+	r.collect = false
+	r.walkProject()
+
+	for _, scr := range p.Scripts() {
+		r.bindMacros(scr)
+		slices.SortStableFunc(b.Scripts[scr], func(x, y Binding) int {
+			return x.Ident.Loc.Index - y.Ident.Loc.Index
+		})
+	}
+	for key, scope := range r.scopes {
+		switch key := key.(type) {
+		case project.Resource:
+			if sym := b.Globals[key.GetName()]; sym != nil && sym.Resource == key {
+				sym.Members = scope.members
+			}
+		case *ast.FuncDecl:
+			if key.Name != nil && b.Globals[key.Name.Value] != nil && b.Globals[key.Name.Value].Decl == key {
+				b.Globals[key.Name.Value].Members = scope.members
+			}
+		}
+	}
+	return b
+}
+
+func (r *resolver) declareGlobals() {
+	b := r.b
+	for _, res := range r.p.Resources {
+		if res.GetName() != "" {
+			b.Globals[res.GetName()] = &Symbol{Name: res.GetName(), Kind: SK_Asset, Resource: res}
+		}
+	}
+	for name := range r.p.ExtensionConstants() {
+		b.Globals[name] = &Symbol{Name: name, Kind: SK_Macro}
+	}
+	for name, fn := range r.p.ExtensionFunctions() {
+		ext := r.p.ResourceOfKind("GMExtension", fn.Extension)
+		b.Globals[name] = &Symbol{Name: name, Kind: SK_Function, Resource: ext}
+	}
+	for _, scr := range r.p.Scripts() {
+		for name := range scr.Macros {
+			b.Globals[name] = &Symbol{Name: name, Kind: SK_Macro, Script: scr}
+		}
+	}
+
+	for _, scr := range r.p.Scripts() {
+		ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Enum:
+				sym := &Symbol{Name: n.Name.Value, Kind: SK_Enum, Script: scr, Decl: n, Members: make(map[string]*Symbol)}
+				for _, m := range n.Members {
+					sym.Members[m.Name.Value] = &Symbol{Name: m.Name.Value, Kind: SK_Enum, Owner: sym.Name, Script: scr, Decl: n}
+				}
+				b.Globals[sym.Name] = sym
+			case *ast.VarDecl:
+				if n.Keyword() == "globalvar" {
+					sym := &Symbol{Name: n.Name.Value, Kind: SK_Globalvar, Script: scr, Decl: n}
+					b.Globals[sym.Name] = sym
+					b.GlobalVars[sym.Name] = sym
+				}
+			}
+			return true
+		})
+	}
+
+	// Library scripts standing in for the summary have no code, but
+	// declare what the summary says they do.
+	for _, scr := range r.p.Scripts() {
+		sum := scr.Summary
+		if sum == nil {
+			continue
+		}
+		for name, members := range sum.Enums {
+			sym := &Symbol{Name: name, Kind: SK_Enum, Script: scr, Members: make(map[string]*Symbol)}
+			for _, m := range members {
+				sym.Members[m] = &Symbol{Name: m, Kind: SK_Enum, Owner: name, Script: scr}
+			}
+			b.Globals[name] = sym
+		}
+		for name := range sum.Globalvars {
+			sym := &Symbol{Name: name, Kind: SK_Globalvar, Script: scr}
+			b.Globals[name] = sym
+			b.GlobalVars[name] = sym
+		}
+		for name := range sum.Globals {
+			if b.GlobalVars[name] == nil {
+				b.GlobalVars[name] = &Symbol{Name: name, Kind: SK_Global, Script: scr}
+			}
+		}
+	}
+
+	declared := r.p.ScriptFunctions()
+	for _, res := range r.p.Resources {
+		scr, ok := res.(*project.ResGMScript)
+		if !ok {
+			continue
+		}
+		if len(scr.Ast.Children) == 0 {
+			for name, decl := range declared {
+				if decl == res {
+					b.Globals[name] = &Symbol{Name: name, Kind: SK_Function, Resource: res}
+				}
+			}
+			continue
+		}
+		for _, stmt := range scr.Ast.Children {
+			if fn, ok := stmt.(*ast.FuncDecl); ok && fn.Name != nil {
+				b.Globals[fn.Name.Value] = &Symbol{Name: fn.Name.Value, Kind: SK_Function, Script: scr, Decl: fn, Resource: res}
+			}
+		}
+	}
+}
+
+func (r *resolver) walkProject() {
+	for _, res := range r.p.Resources {
+		switch res := res.(type) {
+		case *project.ResGMScript:
+			r.walkScript(res, r.unknownSelf, r.unknownSelf, true)
+		case *project.ResGMObject:
+			self := r.objectScope(res)
+			for i := range res.Events {
+				ev := &res.Events[i]
+				other := r.unknownSelf
+				if ev.Type == project.GME_Collision {
+					if obj, ok := r.p.ResourceOfKind("GMObject", ev.Collision).(*project.ResGMObject); ok {
+						other = r.objectScope(obj)
+					}
+				}
+				r.walkScript(&ev.ResGMScript, self, other, false)
+			}
+		case scriptsResource:
+			for _, scr := range res.Scripts() {
+				r.walkScript(scr, r.unknownSelf, r.unknownSelf, false)
+			}
+		}
+	}
+}
+
+func (r *resolver) walkScript(scr *project.ResGMScript, self, other *selfScope, global_funcs bool) {
+	r.script = scr
+	r.globalFuncs = global_funcs
+	r.self = self
+	r.other = other
+	r.fn = &funcScope{
+		locals:  make(map[string]*Symbol),
+		statics: make(map[string]*Symbol),
+		top:     true,
+	}
+	r.visit(&scr.Ast)
+}
+
+// scope returns the self scope belonging to key, creating it if needed.
+func (r *resolver) scope(key any, owner string, is_struct bool) *selfScope {
+	if s, ok := r.scopes[key]; ok {
+		return s
+	}
+	s := &selfScope{owner: owner, members: make(map[string]*Symbol), isStruct: is_struct}
+	r.scopes[key] = s
+	return s
+}
+
+// objectScope returns the self scope of an object, which starts out with
+// its Variable Definitions and inherits from its parent.
+func (r *resolver) objectScope(obj *project.ResGMObject) *selfScope {
+	s := r.scope(obj, obj.GetName(), false)
+	if s.linked {
+		return s
+	}
+	s.linked = true
+
+	for _, name := range obj.Properties {
+		sym := &Symbol{Name: name, Kind: SK_Instance, Owner: s.owner, Resource: obj}
+		s.members[name] = sym
+		if _, ok := r.b.instances[name]; !ok {
+			r.b.instances[name] = sym
+		}
+	}
+	if obj.ParentObject != "" {
+		s.parent = r.unknownSelf
+		if parent, ok := r.p.ResourceOfKind("GMObject", obj.ParentObject).(*project.ResGMObject); ok {
+			s.parent = r.objectScope(parent)
+		}
+	}
+	return s
+}
+
+// constructorScope returns the self scope of a constructor, which
+// inherits from the constructor it calls as its parent.
+func (r *resolver) constructorScope(n *ast.FuncDecl, name string) *selfScope {
+	s := r.scope(n, name, true)
+	if s.linked {
+		return s
+	}
+	s.linked = true
+
+	if n.Parent != nil {
+		s.parent = r.unknownSelf
+		if ident := identOf(n.Parent.Function); ident != nil {
+			sym := r.b.Globals[ident.Value]
+			if sym != nil {
+				if decl, ok := sym.Decl.(*ast.FuncDecl); ok && decl.IsConstructor {
+					s.parent = r.constructorScope(decl, sym.Name)
+				}
+			}
+		}
+	}
+	return s
+}
+
+func (r *resolver) bind(ident *parser.Token, sym *Symbol) {
+	if r.collect {
+		return
+	}
+	r.b.byToken[ident] = sym
+	if ident.Flags&parser.TF_MACRO == 0 {
+		r.b.Scripts[r.script] = append(r.b.Scripts[r.script], Binding{ident, sym})
+	}
+}
+
+// bindMacros binds the uses of macros, which the AST doesn't have, as
+// they are expanded before parsing.
+func (r *resolver) bindMacros(scr *project.ResGMScript) {
+	ts, err := parser.Pretokenize(scr.Script)
+	if err != nil {
+		return
+	}
+	for i := range ts {
+		if ts[i].Type != parser.T_IDENT {
+			continue
+		}
+		if sym := r.b.Globals[ts[i].Value]; sym != nil && sym.Kind == SK_Macro {
+			r.b.Scripts[scr] = append(r.b.Scripts[scr], Binding{&ts[i], sym})
+		}
+	}
+}
+
+func (r *resolver) builtin(name string) *Symbol {
+	if sym, ok := r.b.builtins[name]; ok {
+		return sym
+	}
+	bi, ok := r.b.Builtins[name]
+	if !ok {
+		return nil
+	}
+	sym := &Symbol{Name: name, Kind: SK_Builtin, Builtin: bi}
+	r.b.builtins[name] = sym
+	return sym
+}
+
+func (r *resolver) unknown(name string) *Symbol {
+	if sym, ok := r.b.unknown[name]; ok {
+		return sym
+	}
+	sym := &Symbol{Name: name, Kind: SK_Unknown}
+	r.b.unknown[name] = sym
+	return sym
+}
+
+// missing returns the symbol of a name that is read but doesn't resolve.
+// When it is an instance variable of something other than self, the
+// symbol is kept apart for each self, with self as its Owner.
+func (r *resolver) missing(name string) *Symbol {
+	if r.self.members == nil || r.b.instances[name] == nil {
+		return r.unknown(name)
+	}
+	key := r.self.owner + "." + name
+	if sym, ok := r.b.unknown[key]; ok {
+		return sym
+	}
+	sym := &Symbol{Name: name, Kind: SK_Unknown, Owner: r.self.owner}
+	r.b.unknown[key] = sym
+	return sym
+}
+
+// member returns the instance variable of self, or of what self inherits
+// from, called name, or nil.
+func (r *resolver) member(name string) *Symbol {
+	// GameMaker doesn't allow cycles of parents, but the depth limit
+	// keeps a broken project from hanging us.
+	s := r.self
+	for depth := 0; s != nil && depth < 64; depth++ {
+		if s.members == nil {
+			return r.b.instances[name]
+		}
+		if sym := s.members[name]; sym != nil {
+			return sym
+		}
+		s = s.parent
+	}
+	return nil
+}
+
+// define records an instance variable of self.
+func (r *resolver) define(name string, decl ast.Node, kind SYMBOL_KIND) *Symbol {
+	if _, ok := r.b.instances[name]; !ok {
+		r.b.instances[name] = &Symbol{Name: name, Kind: SK_Instance, Script: r.script, Decl: decl}
+	}
+	if r.self.members == nil {
+		return r.b.instances[name]
+	}
+	if sym, ok := r.self.members[name]; ok {
+		return sym
+	}
+	sym := &Symbol{Name: name, Kind: kind, Owner: r.self.owner, Script: r.script, Decl: decl}
+	r.self.members[name] = sym
+	return sym
+}
+
+// lookup finds what a bare name refers to, or returns nil.
+func (r *resolver) lookup(name string) *Symbol {
+	if sym := r.fn.locals[name]; sym != nil {
+		return sym
+	}
+	if sym := r.fn.statics[name]; sym != nil {
+		return sym
+	}
+	if sym := r.builtin(name); sym != nil && !(r.self.isStruct && sym.Builtin.Kind == BK_INSTANCE) {
+		return sym
+	}
+	if sym := r.b.Globals[name]; sym != nil {
+		return sym
+	}
+	return r.member(name)
+}
+
+func (r *resolver) local(ident *parser.Token, kind SYMBOL_KIND, decl ast.Node) {
+	sym, ok := r.fn.locals[ident.Value]
+	if !ok {
+		sym = &Symbol{Name: ident.Value, Kind: kind, Owner: r.fn.name, Script: r.script, Decl: decl}
+		r.fn.locals[ident.Value] = sym
+	}
+	r.bind(ident, sym)
+}
+
+// assigned resolves a name that is being assigned to. A plain assignment
+// to a name that isn't otherwise known declares an instance variable.
+func (r *resolver) assigned(ident *parser.Token, plain bool, decl ast.Node) {
+	sym := r.lookup(ident.Value)
+	if sym == nil && plain {
+		sym = r.define(ident.Value, decl, SK_Instance)
+	}
+	if sym == nil {
+		sym = r.missing(ident.Value)
+	}
+	r.bind(ident, sym)
+}
+
+func identOf(n ast.Node) *parser.Token {
+	if s, ok := n.(*ast.Simple); ok && s.Type == ast.AST_IDENT {
+		return s.Value
+	}
+	return nil
+}
+
+func isIdent(n ast.Node, name string) bool {
+	ident := identOf(n)
+	return ident != nil && ident.Value == name
+}
+
+// visit resolves the identifiers in n, and returns the symbol n refers to
+// if it is a bare identifier.
+func (r *resolver) visit(n ast.Node) *Symbol {
+	switch n := n.(type) {
+	case *ast.Simple:
+		if n.Type != ast.AST_IDENT || n.Value.Value == "exit" {
+			return nil
+		}
+		sym := r.lookup(n.Value.Value)
+		if sym == nil {
+			sym = r.missing(n.Value.Value)
+		}
+		r.bind(n.Value, sym)
+		return sym
+
+	case *ast.VarDecl:
+		r.varDecl(n)
+
+	case *ast.Assign:
+		r.assign(n)
+
+	case *ast.Attr:
+		r.attr(n, false, nil)
+
+	case *ast.Struct:
+		r.structLiteral(n)
+
+	case *ast.FuncDecl:
+		r.funcDecl(n)
+
+	case *ast.Enum:
+		sym := r.b.Globals[n.Name.Value]
+		r.bind(&n.Name, sym)
+		for i := range n.Members {
+			m := &n.Members[i]
+			if m.Value != nil {
+				r.visit(m.Value)
+			}
+			if member := sym.Members[m.Name.Value]; member != nil {
+				r.bind(&m.Name, member)
+			}
+		}
+
+	case *ast.BlockStmt:
+		if n.Type != ast.AST_WITH {
+			r.visitChildren(n)
+			return nil
+		}
+		self := r.unknownSelf
+		target := r.visit(n.Condition)
+		if target != nil && target.Kind == SK_Asset {
+			if obj, ok := target.Resource.(*project.ResGMObject); ok {
+				self = r.objectScope(obj)
+			}
+		} else if target != nil && target.Kind == SK_Builtin && target.Name == "self" {
+			self = r.self
+		} else if target != nil && target.Kind == SK_Builtin && target.Name == "other" {
+			self = r.other
+		}
+		outer, outer_other := r.self, r.other
+		r.self, r.other = self, r.self
+		r.visit(n.Body)
+		r.self, r.other = outer, outer_other
+
+	case *ast.TryCatch:
+		r.visit(&n.TryBlock)
+		if n.CatchBlock != nil {
+			r.local(&n.CatchBlock.Ident, SK_Local, n.CatchBlock)
+			r.visit(&n.CatchBlock.Block)
+		}
+		if n.FinallyBlock != nil {
+			r.visit(n.FinallyBlock)
+		}
+
+	default:
+		r.visitChildren(n)
+	}
+	return nil
+}
+
+func (r *resolver) visitChildren(n ast.Node) {
+	for _, c := range ast.Children(n) {
+		r.visit(c)
+	}
+}
+
+func (r *resolver) varDecl(n *ast.VarDecl) {
+	if n.Value != nil {
+		r.visit(n.Value)
+	}
+
+	switch n.Keyword() {
+	case "globalvar":
+		r.bind(n.Name, r.b.Globals[n.Name.Value])
+
+	case "static":
+		sym, ok := r.fn.statics[n.Name.Value]
+		if !ok {
+			owner := r.self
+			if r.fn.decl == nil || !r.fn.decl.IsConstructor {
+				owner = &selfScope{}
+			}
+			sym, ok = owner.members[n.Name.Value]
+			if !ok {
+				sym = &Symbol{Name: n.Name.Value, Kind: SK_Static, Owner: r.fn.name, Script: r.script, Decl: n}
+				if owner.members != nil {
+					owner.members[sym.Name] = sym
+				}
+			}
+			r.fn.statics[sym.Name] = sym
+		}
+		r.bind(n.Name, sym)
+
+	default:
+		r.local(n.Name, SK_Local, n)
+	}
+}
+
+func (r *resolver) assign(n *ast.Assign) {
+	r.visit(n.Right)
+	plain := n.Op.Type == parser.T_ASSIGN
+
+	switch left := n.Left.(type) {
+	case *ast.Simple:
+		if left.Type == ast.AST_IDENT {
+			r.assigned(left.Value, plain, n)
+			return
+		}
+
+	case *ast.Attr:
+		r.attr(left, plain, n)
+		return
+
+	case *ast.Access:
+		// Assigning to an index of an unknown name creates an array.
+		acc := left
+		for {
+			r.visit(acc.Access)
+			if acc.SecondAccess != nil {
+				r.visit(acc.SecondAccess)
+			}
+			if inner, ok := acc.Value.(*ast.Access); ok {
+				acc = inner
+				continue
+			}
+			break
+		}
+		creates := acc.Type.Type == parser.T_LSQUARE || acc.Type.Type == parser.T_ACC_ARRAY
+		if ident := identOf(acc.Value); ident != nil {
+			r.assigned(ident, plain && creates, n)
+		} else {
+			r.visit(acc.Value)
+		}
+		return
+	}
+
+	r.visit(n.Left)
+}
+
+// attr resolves global.name, self.name, other.name and Enum.Member. Other
+// attributes depend on the type of what they're read from.
+func (r *resolver) attr(n *ast.Attr, plain bool, decl ast.Node) {
+	target := r.visit(n.Value)
+	name := n.Name.Value
+
+	switch {
+	case isIdent(n.Value, "global"):
+		sym := r.b.GlobalVars[name]
+		if sym == nil && plain {
+			sym = &Symbol{Name: name, Kind: SK_Global, Script: r.script, Decl: decl}
+			r.b.GlobalVars[name] = sym
+		}
+		if sym == nil {
+			sym = r.unknown("global." + name)
+		}
+		r.bind(n.Name, sym)
+
+	case isIdent(n.Value, "self"), isIdent(n.Value, "other"):
+		outer := r.self
+		if isIdent(n.Value, "other") {
+			r.self = r.other
+		}
+		sym := r.builtin(name)
+		if sym == nil || sym.Builtin.Kind != BK_INSTANCE || r.self.isStruct {
+			sym = r.member(name)
+		}
+		if sym == nil && plain {
+			sym = r.define(name, decl, SK_Instance)
+		}
+		if sym == nil {
+			sym = r.missing(name)
+		}
+		r.bind(n.Name, sym)
+		r.self = outer
+
+	case target != nil && target.Kind == SK_Enum && target.Members != nil:
+		sym := target.Members[name]
+		if sym == nil {
+			sym = r.unknown(target.Name + "." + name)
+		}
+		r.bind(n.Name, sym)
+	}
+}
+
+func (r *resolver) structLiteral(n *ast.Struct) {
+	scope := r.scope(n, "", true)
+	for i := range n.Fields {
+		f := &n.Fields[i]
+		outer := r.self
+		r.self = scope
+		sym := r.define(f.Name.Value, f, SK_Instance)
+		r.self = outer
+		r.bind(f.Name, sym)
+
+		if fn, ok := f.Value.(*ast.FuncDecl); ok {
+			r.function(fn, scope, f.Name.Value)
+		} else if f.Value != nil {
+			r.visit(f.Value)
+		}
+	}
+}
+
+func (r *resolver) funcDecl(n *ast.FuncDecl) {
+	if n.Name == nil {
+		r.function(n, r.self, "")
+		return
+	}
+
+	if r.fn.top && r.globalFuncs {
+		r.bind(n.Name, r.b.Globals[n.Name.Value])
+		r.function(n, r.unknownSelf, n.Name.Value)
+		return
+	}
+
+	// Named functions anywhere else are methods of self.
+	sym := r.lookup(n.Name.Value)
+	if sym == nil {
+		sym = r.define(n.Name.Value, n, SK_Instance)
+	}
+	r.bind(n.Name, sym)
+	r.function(n, r.self, n.Name.Value)
+}
+
+// function resolves a function's body with self set to self, or to the
+// struct it constructs.
+func (r *resolver) function(n *ast.FuncDecl, self *selfScope, name string) {
+	outer_fn, outer_self, outer_other := r.fn, r.self, r.other
+	r.fn = &funcScope{
+		decl:    n,
+		name:    name,
+		locals:  make(map[string]*Symbol),
+		statics: make(map[string]*Symbol),
+	}
+	// Functions can be called from anywhere, so other isn't known in them.
+	r.self, r.other = self, r.unknownSelf
+	if n.IsConstructor {
+		r.self = r.constructorScope(n, name)
+	}
+
+	for i := range n.Args {
+		arg := &n.Args[i]
+		if arg.Default != nil {
+			r.visit(arg.Default)
+		}
+		r.local(arg.Name, SK_Argument, arg)
+	}
+	if n.Parent != nil {
+		r.visit(n.Parent)
+	}
+	r.visit(&n.Body)
+
+	r.fn, r.self, r.other = outer_fn, outer_self, outer_other
+}
@@ -0,0 +1,344 @@
+package typecheck
+
+import (
+	"fmt"
+	"gmtc/project"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testProject builds a project in memory from pairs of names and GML, and
+// parses it. Scripts are given by name and object events by the object
+// and the event's script, e.g. "obj_a/Create_0" or
+// "obj_a/Collision_obj_b". "obj_a/parent" gives an object's parent
+// instead of code.
+func testProject(t *testing.T, sources ...string) *project.Project {
+	t.Helper()
+	fsys := project.MemFS{}
+	scripts := make([]string, 0)
+	objects := make([]string, 0)
+	events := make(map[string][]string)
+	parents := make(map[string]string)
+
+	for i := 0; i+1 < len(sources); i += 2 {
+		name, code := sources[i], sources[i+1]
+		obj, event, ok := strings.Cut(name, "/")
+		if !ok {
+			scripts = append(scripts, name)
+			fsys[path.Join("scripts", name, name+".yy")] = []byte(fmt.Sprintf(
+				`{"name":%q,"resourceType":"GMScript","resourceVersion":"1.0"}`, name,
+			))
+			fsys[path.Join("scripts", name, name+".gml")] = []byte(code)
+			continue
+		}
+		if !slices.Contains(objects, obj) {
+			objects = append(objects, obj)
+		}
+		if event == "parent" {
+			parents[obj] = code
+			if !slices.Contains(objects, code) {
+				objects = append(objects, code)
+			}
+			continue
+		}
+
+		type_name, num, _ := strings.Cut(event, "_")
+		evtype, err := project.ParseEventType(type_name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		collision := "null"
+		if evtype == project.GME_Collision {
+			collision = fmt.Sprintf(`{"name":%q,"path":"objects/%v/%v.yy"}`, num, num, num)
+			num = "0"
+		}
+		events[obj] = append(events[obj], fmt.Sprintf(
+			`{"eventNum":%v,"eventType":%v,"collisionObjectId":%v,"resourceType":"GMEvent"}`,
+			num, evtype.Number(), collision,
+		))
+		fsys[path.Join("objects", obj, event+".gml")] = []byte(code)
+	}
+
+	resources := make([]string, 0)
+	for _, name := range scripts {
+		resources = append(resources, fmt.Sprintf(`{"id":{"name":%q,"path":"scripts/%v/%v.yy"}}`, name, name, name))
+	}
+	for _, name := range objects {
+		parent := "null"
+		if parents[name] != "" {
+			parent = fmt.Sprintf(`{"name":%q,"path":"objects/%v/%v.yy"}`, parents[name], parents[name], parents[name])
+		}
+		fsys[path.Join("objects", name, name+".yy")] = []byte(fmt.Sprintf(
+			`{"name":%q,"resourceType":"GMObject","resourceVersion":"1.0","parentObjectId":%v,"eventList":[%v]}`,
+			name, parent, strings.Join(events[name], ","),
+		))
+		resources = append(resources, fmt.Sprintf(`{"id":{"name":%q,"path":"objects/%v/%v.yy"}}`, name, name, name))
+	}
+	fsys["p.yyp"] = []byte(fmt.Sprintf(
+		`{"name":"p","resourceType":"GMProject","resourceVersion":"1.4","resources":[%v]}`,
+		strings.Join(resources, ","),
+	))
+
+	p, err := project.LoadProjectFS(fsys, "p.yyp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Parse()
+	if errs := p.AllErrors(); len(errs) > 0 {
+		t.Fatalf("test project doesn't parse:\n%v", errs.Merge())
+	}
+	return &p
+}
+
+// testScript returns the script of a test project with the given name,
+// as it was passed to testProject.
+func testScript(t *testing.T, p *project.Project, name string) *project.ResGMScript {
+	t.Helper()
+	want := path.Join("scripts", name, name+".gml")
+	if obj, event, ok := strings.Cut(name, "/"); ok {
+		want = path.Join("objects", obj, event+".gml")
+	}
+	for _, scr := range p.Scripts() {
+		if scr.GMLPath == want {
+			return scr
+		}
+	}
+	t.Fatalf("no script %v", name)
+	return nil
+}
+
+// symbols lists what each identifier of a script refers to, as
+// "name Kind" or "name Kind Owner".
+func symbols(b *Bindings, scr *project.ResGMScript) []string {
+	out := make([]string, 0)
+	for _, bd := range b.Scripts[scr] {
+		s := bd.Ident.Value + " " + bd.Symbol.Kind.String()
+		if bd.Symbol.Owner != "" {
+			s += " " + bd.Symbol.Owner
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func checkSymbols(t *testing.T, b *Bindings, scr *project.ResGMScript, want ...string) {
+	t.Helper()
+	if got := symbols(b, scr); !slices.Equal(got, want) {
+		t.Errorf("%v binds:\n\t%v\nwant:\n\t%v", scr.GMLPath, strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
+
+func TestResolveScript(t *testing.T) {
+	p := testProject(t, "scr_a", `
+#macro SPEED 4
+enum Dir { Left, Right }
+globalvar score_total;
+function scr_a(_x) {
+	var _y = _x + SPEED;
+	global.hits = Dir.Left;
+	return scr_b(_y, undefined_thing);
+}
+`, "scr_b", `
+function scr_b() {
+	score_total = global.hits;
+	show_debug_message(spr_player);
+}
+`)
+	b := Resolve(p, DefaultBuiltins())
+
+	checkSymbols(t, b, testScript(t, p, "scr_a"),
+		"SPEED Macro",
+		"Dir Enum",
+		"Left Enum Dir",
+		"Right Enum Dir",
+		"score_total Globalvar",
+		"scr_a Function",
+		"_x Argument scr_a",
+		"_y Local scr_a",
+		"_x Argument scr_a",
+		"SPEED Macro",
+		"global Builtin",
+		"hits Global",
+		"Dir Enum",
+		"Left Enum Dir",
+		"scr_b Function",
+		"_y Local scr_a",
+		"undefined_thing Unknown",
+	)
+	checkSymbols(t, b, testScript(t, p, "scr_b"),
+		"scr_b Function",
+		"score_total Globalvar",
+		"global Builtin",
+		"hits Global",
+		"show_debug_message Builtin",
+		"spr_player Unknown",
+	)
+}
+
+func TestResolveScopes(t *testing.T) {
+	p := testProject(t, "scr_a", `
+function scr_a() {
+	var _n = 1;
+	counter = 0;
+	function inner() {
+		return _n + counter;
+	}
+	var _f = function() { return _n; };
+}
+function Vec(_x) constructor {
+	x = _x;
+	static zero = function() { return new Vec(0); };
+	function len() { return abs(x); }
+}
+function Vec3(_x, _z) : Vec(_x) constructor {
+	z = _z;
+	function len3() { return x + z + y; }
+}
+`, "obj_a/Create_0", `
+hp = 10;
+speed = hp;
+`, "obj_b/parent", "obj_a", "obj_b/Step_0", `
+hp -= 1;
+mana += 1;
+`)
+	b := Resolve(p, DefaultBuiltins())
+
+	// Locals aren't visible in nested functions, and assigning a
+	// variable in a script function makes it an instance variable of
+	// whatever calls it.
+	checkSymbols(t, b, testScript(t, p, "scr_a"),
+		"scr_a Function",
+		"_n Local scr_a",
+		"counter Instance",
+		"inner Instance",
+		"_n Unknown",
+		"counter Instance",
+		"_f Local scr_a",
+		"_n Unknown",
+		"Vec Function",
+		"_x Argument Vec",
+		"x Instance Vec",
+		"_x Argument Vec",
+		"zero Static Vec",
+		"Vec Function",
+		"len Instance Vec",
+		"abs Builtin",
+		"x Instance Vec",
+		"Vec3 Function",
+		"_x Argument Vec3",
+		"_z Argument Vec3",
+		"Vec Function",
+		"_x Argument Vec3",
+		"z Instance Vec3",
+		"_z Argument Vec3",
+		"len3 Instance Vec3",
+		"x Instance Vec",
+		"z Instance Vec3",
+		"y Unknown",
+	)
+
+	// Objects have the built-in instance variables and inherit their
+	// parent's.
+	checkSymbols(t, b, testScript(t, p, "obj_a/Create_0"),
+		"hp Instance obj_a",
+		"speed Builtin",
+		"hp Instance obj_a",
+	)
+	checkSymbols(t, b, testScript(t, p, "obj_b/Step_0"),
+		"hp Instance obj_a",
+		"mana Unknown",
+	)
+
+	if members := keys(b.Globals["obj_a"].Members); !slices.Equal(members, []string{"hp"}) {
+		t.Errorf("obj_a has members %v", members)
+	}
+}
+
+func TestBindingsAt(t *testing.T) {
+	p := testProject(t, "scr_a", "function scr_a(_value) {\n\treturn _value * 2;\n}\n")
+	b := Resolve(p, DefaultBuiltins())
+	scr := testScript(t, p, "scr_a")
+
+	tests := []struct {
+		line, char int
+		want       string
+	}{
+		{0, 9, "scr_a"},
+		{0, 13, "scr_a"},
+		{0, 14, ""},
+		{0, 15, "_value"},
+		{1, 8, "_value"},
+		{1, 13, "_value"},
+		{1, 14, ""},
+		{2, 0, ""},
+	}
+	for _, test := range tests {
+		got := ""
+		if bd := b.At(scr, test.line, test.char); bd != nil {
+			got = bd.Ident.Value
+			if b.Of(bd.Ident) != bd.Symbol {
+				t.Errorf("Of and At disagree about %v", got)
+			}
+		}
+		if got != test.want {
+			t.Errorf("%v:%v is %q, want %q", test.line, test.char, got, test.want)
+		}
+	}
+}
+
+func TestResolveWith(t *testing.T) {
+	p := testProject(t, "obj_a/Create_0", `
+mana = 5;
+with (obj_b) {
+	hp = other.mana;
+	var _me = self;
+	var _them = other;
+}
+var _s = {
+	size: 1,
+	grow: function() { size += 1; return self; },
+};
+`, "obj_b/Create_0", `
+hp = 10;
+`, "obj_a/Collision_obj_b", `
+other.hp -= 1;
+var _hit = other;
+`)
+	b := Resolve(p, DefaultBuiltins())
+
+	// In a with block self is the instances iterated over and other the
+	// instance that runs it; in a collision event other is the instance
+	// collided with; functions in a struct literal are its methods.
+	checkSymbols(t, b, testScript(t, p, "obj_a/Create_0"),
+		"mana Instance obj_a",
+		"obj_b Asset",
+		"hp Instance obj_b",
+		"other Builtin",
+		"mana Instance obj_a",
+		"_me Local",
+		"self Builtin",
+		"_them Local",
+		"other Builtin",
+		"_s Local",
+		"size Instance",
+		"grow Instance",
+		"size Instance",
+		"self Builtin",
+	)
+	checkSymbols(t, b, testScript(t, p, "obj_a/Collision_obj_b"),
+		"other Builtin",
+		"hp Instance obj_b",
+		"_hit Local",
+		"other Builtin",
+	)
+
+	types := Infer(p, b)
+	checkDeclarations(t, types, testScript(t, p, "obj_a/Create_0"),
+		"_me: Id.Instance.obj_b",
+		"_them: Id.Instance.obj_a",
+		"_s: Struct{size: Real, grow: Function() -> Struct | Id.Instance}",
+	)
+	checkDeclarations(t, types, testScript(t, p, "obj_a/Collision_obj_b"), "_hit: Id.Instance.obj_b")
+}
@@ -0,0 +1,191 @@
+package typecheck
+
+import (
+	"fmt"
+	"gmtc/ast"
+	"gmtc/parser"
+	"gmtc/project"
+	"gmtc/utils"
+	"slices"
+)
+
+type FieldError struct{ error }
+
+// Shape returns the struct the constructor called name makes: the fields
+// it and the functions declared in it assign, its statics, and what it
+// inherits from its parent. It is nil if there is no such constructor.
+func (t *Types) Shape(name string) *Type {
+	shape, _ := t.shape(name, 0)
+	return shape
+}
+
+// shape also reports whether every field of the struct is known, which
+// isn't the case if its parent isn't a constructor of the project.
+func (t *Types) shape(name string, depth int) (*Type, bool) {
+	sym := t.b.Globals[name]
+	if sym == nil || depth > 64 {
+		return nil, false
+	}
+	fn, ok := sym.Decl.(*ast.FuncDecl)
+	if !ok || !fn.IsConstructor {
+		return nil, false
+	}
+	t.Func(fn)
+
+	fields := make([]Field, 0, len(sym.Members))
+	complete := true
+	if fn.Parent != nil {
+		var parent *Type
+		if ident := identOf(fn.Parent.Function); ident != nil {
+			parent, complete = t.shape(ident.Value, depth+1)
+		}
+		if parent != nil {
+			fields = append(fields, parent.Fields...)
+		} else {
+			complete = false
+		}
+	}
+
+	names := keys(sym.Members)
+	slices.Sort(names)
+	for _, name := range names {
+		m := sym.Members[name]
+		ty := t.members[m]
+		if ty == nil {
+			ty = t.symbols[m]
+		}
+		if ty == nil {
+			ty = Any
+		}
+
+		i := slices.IndexFunc(fields, func(f Field) bool { return f.Name == name })
+		if i >= 0 {
+			fields[i].Type = ty
+		} else {
+			fields = append(fields, Field{Name: name, Type: ty})
+		}
+	}
+
+	added := keys(t.added[name])
+	slices.Sort(added)
+	for _, field := range added {
+		i := slices.IndexFunc(fields, func(f Field) bool { return f.Name == field })
+		if i >= 0 {
+			fields[i].Type = Join(fields[i].Type, t.added[name][field])
+		} else {
+			fields = append(fields, Field{Name: field, Type: t.added[name][field]})
+		}
+	}
+	return StructOf(name, fields...), complete
+}
+
+// addShapeField records a field assigned to a struct made by a
+// constructor, so that its shape has it wherever the struct goes.
+func (t *Types) addShapeField(st *Type, name string, ty *Type) {
+	if st.Kind != TK_Struct || st.Name == "" {
+		return
+	}
+	if t.added[st.Name] == nil {
+		t.added[st.Name] = make(map[string]*Type)
+	}
+	t.added[st.Name][name] = Join(t.added[st.Name][name], ty)
+}
+
+// inherits reports whether the constructor called child has the one
+// called parent among its ancestors.
+func (t *Types) inherits(child, parent string) bool {
+	for depth := 0; depth < 64; depth++ {
+		sym := t.b.Globals[child]
+		if sym == nil {
+			return false
+		}
+		fn, ok := sym.Decl.(*ast.FuncDecl)
+		if !ok || !fn.IsConstructor || fn.Parent == nil {
+			return false
+		}
+		ident := identOf(fn.Parent.Function)
+		if ident == nil {
+			return false
+		}
+		if ident.Value == parent {
+			return true
+		}
+		child = ident.Value
+	}
+	return false
+}
+
+// field returns the type of a field of a struct or of an instance of an
+// object, or nil if it isn't known to have it.
+func (t *Types) field(st *Type, name string) *Type {
+	if st.Kind == TK_Id && st.Object != "" {
+		return t.instanceField(st.Object, name)
+	}
+	if st.Kind != TK_Struct {
+		return nil
+	}
+	if st.Name == "" {
+		return st.Field(name)
+	}
+	if shape := t.Shape(st.Name); shape != nil {
+		return shape.Field(name)
+	}
+	return nil
+}
+
+// fieldsKnown reports whether every field of a struct is known, so that
+// reading any other one is a mistake. Empty struct literals are left out,
+// as their fields are usually added later.
+func (t *Types) fieldsKnown(st *Type) (*Type, bool) {
+	if st.Kind != TK_Struct {
+		return nil, false
+	}
+	if st.Name == "" {
+		return st, len(st.Fields) > 0
+	}
+	return t.shape(st.Name, 0)
+}
+
+// CheckFields reports reads of fields that structs made by constructors,
+// or by struct literals, don't have. Library resources aren't checked.
+func CheckFields(p *project.Project, t *Types) utils.Errors {
+	out := make(utils.Errors, 0)
+	for _, scr := range checkedScripts(p) {
+		written := make(map[*ast.Attr]bool)
+		ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+			if assign, ok := n.(*ast.Assign); ok && assign.Op.Type == parser.T_ASSIGN {
+				if attr, ok := assign.Left.(*ast.Attr); ok {
+					written[attr] = true
+				}
+			}
+			return true
+		})
+
+		ast.Inspect(&scr.Ast, func(n ast.Node) bool {
+			attr, ok := n.(*ast.Attr)
+			if !ok || written[attr] {
+				return true
+			}
+			st := t.Of(attr.Value)
+			shape, known := t.fieldsKnown(st)
+			if !known || shape.Field(attr.Name.Value) != nil {
+				return true
+			}
+
+			msg := fmt.Sprintf("%v has no field %v", st, attr.Name.Value)
+			names := make([]string, len(shape.Fields))
+			for i, f := range shape.Fields {
+				names[i] = f.Name
+			}
+			if match := closest(attr.Name.Value, names); match != "" {
+				msg += fmt.Sprintf(", did you mean %v?", match)
+			}
+			loc := attr.Name.Loc
+			out = out.AddPrefix(scr.ErrorPrefix(), FieldError{fmt.Errorf(
+				"%v:%v: %v", loc.Line+1, loc.Char+1, msg,
+			)})
+			return true
+		})
+	}
+	return out
+}
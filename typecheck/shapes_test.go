@@ -0,0 +1,82 @@
+package typecheck
+
+import "testing"
+
+func TestShape(t *testing.T) {
+	p := testProject(t, "scr_a", `
+function Vec(_x, _y) constructor {
+	x = _x;
+	y = _y;
+	static zero = function() { return new Vec(0, 0); };
+	function len() { return sqrt(x * x + y * y); }
+}
+function Vec3(_x, _y, _z) : Vec(_x, _y) constructor {
+	z = _z;
+	x = string(_x);
+}
+function Node() : SomeLibraryThing() constructor {
+	next = undefined;
+}
+function not_a_constructor() {}
+var _v = new Vec(1, 2);
+_v.tag = "start";
+`)
+	types := inferTypes(p)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Vec", "Struct{len: Any, x: Any, y: Any, zero: Function() -> Struct.Vec, tag: String}"},
+		// Vec3 has what it inherits from Vec, then its own fields.
+		{"Vec3", "Struct{len: Any, x: Any, y: Any, zero: Function() -> Struct.Vec, tag: String, z: Any}"},
+		{"Node", "Struct{next: Undefined}"},
+	}
+	for _, test := range tests {
+		shape := types.Shape(test.name)
+		if shape == nil {
+			t.Errorf("%v has no shape", test.name)
+			continue
+		}
+		if got := StructOf("", shape.Fields...).String(); got != test.want {
+			t.Errorf("%v is %v, want %v", test.name, got, test.want)
+		}
+	}
+	if types.Shape("not_a_constructor") != nil || types.Shape("nothing") != nil {
+		t.Errorf("got a shape for something that isn't a constructor")
+	}
+}
+
+func TestCheckFields(t *testing.T) {
+	p := testProject(t, "scr_a", `
+function Foo() constructor {
+	count = 0;
+	function bump() { count += 1; }
+}
+function Bar() : Foo() constructor {
+	label = "";
+}
+function Baz() : OutsideTheProject() constructor {}
+
+var _f = new Foo();
+_f.extra = 1;
+show_debug_message(_f.extra);
+show_debug_message(_f.cuont);
+var _b = new Bar();
+show_debug_message(_b.count + _b.label);
+show_debug_message(_b.missing);
+var _z = new Baz();
+show_debug_message(_z.whatever);
+var _lit = { a: 1 };
+show_debug_message(_lit.b);
+var _empty = {};
+show_debug_message(_empty.anything);
+`)
+	// Fields assigned outside the constructor are part of the shape, and
+	// structs whose parent isn't known can have any field.
+	checkErrors(t, CheckFields(p, inferTypes(p)),
+		"scripts/scr_a/scr_a.gml:14:23: Struct.Foo has no field cuont, did you mean count?",
+		"scripts/scr_a/scr_a.gml:17:23: Struct.Bar has no field missing",
+		"scripts/scr_a/scr_a.gml:21:25: Struct{a: Real} has no field b",
+	)
+}
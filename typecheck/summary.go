@@ -0,0 +1,21 @@
+package typecheck
+
+import "gmtc/project"
+
+// SummaryTypes fills in the types of the global variables in a summary
+// of the project b and t were worked out for.
+func SummaryTypes(sum project.Summary, b *Bindings, t *Types) {
+	for _, scr := range sum.Scripts {
+		for _, vars := range []map[string]string{scr.Globalvars, scr.Globals} {
+			for name := range vars {
+				sym := b.GlobalVars[name]
+				if sym == nil {
+					continue
+				}
+				if ty := t.Symbol(sym); ty.Kind != TK_Any {
+					vars[name] = ty.String()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,676 @@
+package typecheck
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Types follow Feather, GameMaker's own checker, so that the types
+// written in its JSDoc comments ("Real", "Array<String>", "Id.DsMap",
+// "Asset.GMSprite", ...) can be used as they are.
+
+type TYPE_KIND int
+
+//go:generate stringer -type=TYPE_KIND -trimprefix=TK_
+const (
+	// TK_Any is both above and below every type: anything can be used
+	// as Any and Any can be used as anything. It is what unknown values
+	// get, so they never cause errors.
+	TK_Any TYPE_KIND = iota
+	// TK_Mixed holds any value, but unlike Any it can only be used
+	// where any value is accepted.
+	TK_Mixed
+	TK_Undefined
+	TK_Real
+	TK_Int64
+	TK_Bool
+	TK_String
+	TK_Pointer
+	TK_Array
+	TK_Struct
+	TK_Function
+	TK_Enum
+	TK_Asset
+	TK_Id
+	TK_Union
+)
+
+type Type struct {
+	Kind TYPE_KIND
+	// Name narrows the kind: the shape of a struct (Struct.Vector2), the
+	// name of an enum, the resource type of an asset (Asset.GMSprite),
+	// the kind of a handle (Id.DsMap), pointer (Pointer.Texture) or
+	// constant (Constant.Colour, a Real).
+	Name string
+	// Params are the element type of arrays and the type arguments of
+	// handles (Id.DsMap<Real>).
+	Params []*Type
+	// Fields are the known fields of a struct.
+	Fields []Field
+	// Func is the signature of a function, if it is known.
+	Func *Signature
+	// Members are the alternatives of a union.
+	Members []*Type
+	// Object is the object of an instance (Id.Instance), if it is known.
+	Object string
+}
+
+type Field struct {
+	Name string
+	Type *Type
+}
+
+type Param struct {
+	Name string
+	Type *Type
+	// Optional parameters may be left out. They are undefined when they
+	// are, unless they have a default value.
+	Optional bool
+}
+
+type Signature struct {
+	Params []Param
+	// Rest is the type of any further arguments, for functions taking
+	// a variable number of them. It is nil for those that don't.
+	Rest     *Type
+	RestName string
+	Return   *Type
+	// Constructor functions are called with new, which returns an
+	// instance of the struct shape named by Return.
+	Constructor bool
+}
+
+var (
+	Any       = &Type{Kind: TK_Any}
+	Mixed     = &Type{Kind: TK_Mixed}
+	Undefined = &Type{Kind: TK_Undefined}
+	Real      = &Type{Kind: TK_Real}
+	Int64     = &Type{Kind: TK_Int64}
+	Bool      = &Type{Kind: TK_Bool}
+	String    = &Type{Kind: TK_String}
+	Pointer   = &Type{Kind: TK_Pointer}
+)
+
+func ArrayOf(elem *Type) *Type {
+	return &Type{Kind: TK_Array, Params: []*Type{elem}}
+}
+
+// StructOf builds a struct shape. name may be "" for shapes that aren't
+// named after a constructor.
+func StructOf(name string, fields ...Field) *Type {
+	return &Type{Kind: TK_Struct, Name: name, Fields: fields}
+}
+
+func FunctionOf(sig *Signature) *Type {
+	return &Type{Kind: TK_Function, Func: sig}
+}
+
+func EnumOf(name string) *Type {
+	return &Type{Kind: TK_Enum, Name: name}
+}
+
+func AssetOf(kind string) *Type {
+	return &Type{Kind: TK_Asset, Name: kind}
+}
+
+func IdOf(kind string, params ...*Type) *Type {
+	return &Type{Kind: TK_Id, Name: kind, Params: params}
+}
+
+// InstanceOf is the type of instances of an object.
+func InstanceOf(object string) *Type {
+	return &Type{Kind: TK_Id, Name: "Instance", Object: object}
+}
+
+// Elem returns the element type of an array, or Any.
+func (t *Type) Elem() *Type {
+	if t.Kind != TK_Array || len(t.Params) == 0 {
+		return Any
+	}
+	return t.Params[0]
+}
+
+// Field returns the type of a struct's field, or nil if the struct isn't
+// known to have it.
+func (t *Type) Field(name string) *Type {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f.Type
+		}
+	}
+	return nil
+}
+
+// MinArgs and MaxArgs are how many arguments a call must and may pass.
+// MaxArgs is -1 if there is no limit.
+func (s *Signature) MinArgs() int {
+	n := 0
+	for i, p := range s.Params {
+		if !p.Optional {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+func (s *Signature) MaxArgs() int {
+	if s.Rest != nil {
+		return -1
+	}
+	return len(s.Params)
+}
+
+// ParamType returns the type expected of the i-th argument, or nil if
+// there can't be one.
+func (s *Signature) ParamType(i int) *Type {
+	if i < len(s.Params) {
+		return s.Params[i].Type
+	}
+	return s.Rest
+}
+
+func (t *Type) String() string {
+	sb := strings.Builder{}
+	t.write(&sb)
+	return sb.String()
+}
+
+func (t *Type) write(sb *strings.Builder) {
+	switch t.Kind {
+	case TK_Union:
+		for i, m := range t.Members {
+			if i > 0 {
+				sb.WriteString(" | ")
+			}
+			m.write(sb)
+		}
+		return
+
+	case TK_Real:
+		if t.Name != "" {
+			sb.WriteString("Constant." + t.Name)
+			return
+		}
+
+	case TK_Struct:
+		sb.WriteString("Struct")
+		if t.Name != "" {
+			sb.WriteString("." + t.Name)
+		} else if len(t.Fields) > 0 {
+			sb.WriteString("{")
+			for i, f := range t.Fields {
+				if i > 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteString(f.Name + ": ")
+				f.Type.write(sb)
+			}
+			sb.WriteString("}")
+		}
+		return
+
+	case TK_Function:
+		sb.WriteString("Function")
+		if t.Name != "" {
+			sb.WriteString("." + t.Name)
+		} else if t.Func != nil {
+			t.Func.write(sb)
+		}
+		return
+	}
+
+	sb.WriteString(t.Kind.String())
+	if t.Name != "" {
+		sb.WriteString("." + t.Name)
+	}
+	if t.Object != "" {
+		sb.WriteString("." + t.Object)
+	}
+	// A plain Array is an Array<Any>, and is written as one.
+	if len(t.Params) > 0 && !(t.Kind == TK_Array && t.Elem().Kind == TK_Any) {
+		sb.WriteString("<")
+		for i, p := range t.Params {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			p.write(sb)
+		}
+		sb.WriteString(">")
+	}
+}
+
+func (s *Signature) write(sb *strings.Builder) {
+	sb.WriteString("(")
+	for i, p := range s.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if p.Name != "" {
+			sb.WriteString(p.Name)
+			if p.Optional {
+				sb.WriteString("?")
+			}
+			sb.WriteString(": ")
+		} else if p.Optional {
+			sb.WriteString("?")
+		}
+		p.Type.write(sb)
+	}
+	if s.Rest != nil {
+		if len(s.Params) > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("..." + s.RestName)
+		if s.RestName != "" {
+			sb.WriteString(": ")
+		}
+		s.Rest.write(sb)
+	}
+	sb.WriteString(")")
+	if s.Return != nil {
+		sb.WriteString(" -> ")
+		s.Return.write(sb)
+	}
+}
+
+// numeric kinds can all be used where a Real or a Bool is expected: GML
+// booleans are reals, any real can be tested as a boolean, and enum
+// members are constant reals.
+func (t *Type) numeric() bool {
+	switch t.Kind {
+	case TK_Real, TK_Int64, TK_Bool, TK_Enum:
+		return true
+	}
+	return false
+}
+
+// SubtypeOf reports whether a value of type t can be used where one of
+// type u is expected. Instances of an object are only instances of that
+// object; SubtypeIn also follows object parents.
+func (t *Type) SubtypeOf(u *Type) bool {
+	return t.SubtypeIn(u, nil)
+}
+
+// SubtypeIn is SubtypeOf for a project where instances of an object are
+// also instances of every object descends reports it as a descendant of.
+func (t *Type) SubtypeIn(u *Type, descends func(object, ancestor string) bool) bool {
+	if t == u || t.Kind == TK_Any || u.Kind == TK_Any || u.Kind == TK_Mixed {
+		return true
+	}
+	if t.Kind == TK_Union {
+		for _, m := range t.Members {
+			if !m.SubtypeIn(u, descends) {
+				return false
+			}
+		}
+		return true
+	}
+	if u.Kind == TK_Union {
+		for _, m := range u.Members {
+			if t.SubtypeIn(m, descends) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch u.Kind {
+	case TK_Real, TK_Int64, TK_Bool:
+		return t.numeric()
+	case TK_Enum:
+		return t.Kind == TK_Enum && (u.Name == "" || t.Name == u.Name)
+	case TK_Asset, TK_Pointer:
+		return t.Kind == u.Kind && (u.Name == "" || t.Name == u.Name)
+	case TK_Id:
+		if t.Kind != TK_Id || (u.Name != "" && t.Name != u.Name) {
+			return false
+		}
+		// Instances of an object that isn't known could be of any.
+		if u.Object != "" && t.Object != "" && t.Object != u.Object && (descends == nil || !descends(t.Object, u.Object)) {
+			return false
+		}
+		return paramsSubtype(t.Params, u.Params, descends)
+	case TK_Array:
+		return t.Kind == TK_Array && t.Elem().SubtypeIn(u.Elem(), descends)
+	case TK_Struct:
+		return t.Kind == TK_Struct && t.structSubtype(u, descends)
+	case TK_Function:
+		return t.Kind == TK_Function && t.funcSubtype(u, descends)
+	}
+
+	return t.Kind == u.Kind
+}
+
+// paramsSubtype compares type arguments. Missing ones are Any.
+func paramsSubtype(ts []*Type, us []*Type, descends func(string, string) bool) bool {
+	for i := 0; i < min(len(ts), len(us)); i++ {
+		if !ts[i].SubtypeIn(us[i], descends) {
+			return false
+		}
+	}
+	return true
+}
+
+// structSubtype: a shape is a subtype of another with the same name, or
+// of an unnamed one whose fields it has.
+func (t *Type) structSubtype(u *Type, descends func(string, string) bool) bool {
+	if u.Name != "" {
+		return t.Name == u.Name
+	}
+	for _, f := range u.Fields {
+		tf := t.Field(f.Name)
+		if tf == nil {
+			if t.Name == "" && len(t.Fields) == 0 {
+				continue
+			}
+			return false
+		}
+		if !tf.SubtypeIn(f.Type, descends) {
+			return false
+		}
+	}
+	return true
+}
+
+// funcSubtype: a function can stand in for another if it accepts every
+// call the other accepts and returns something the other could.
+func (t *Type) funcSubtype(u *Type, descends func(string, string) bool) bool {
+	if u.Name != "" && t.Name != "" {
+		return t.Name == u.Name
+	}
+	if t.Func == nil || u.Func == nil {
+		return true
+	}
+	ts, us := t.Func, u.Func
+
+	if ts.MinArgs() > us.MinArgs() {
+		return false
+	}
+	if ts.MaxArgs() >= 0 && (us.MaxArgs() < 0 || ts.MaxArgs() < us.MaxArgs()) {
+		return false
+	}
+	for i := 0; i < max(len(ts.Params), len(us.Params)); i++ {
+		tp, up := ts.ParamType(i), us.ParamType(i)
+		if tp != nil && up != nil && !up.SubtypeIn(tp, descends) {
+			return false
+		}
+	}
+	if ts.Return != nil && us.Return != nil && !ts.Return.SubtypeIn(us.Return, descends) {
+		return false
+	}
+	return true
+}
+
+// joinsTo reports whether a value that is either a t or a u is just a u.
+// That is the case when t is a subtype of u, except that numbers, which
+// can be used as booleans, don't become booleans.
+func (t *Type) joinsTo(u *Type) bool {
+	return t.SubtypeOf(u) && (u.Kind != TK_Bool || t.Kind == TK_Bool)
+}
+
+// Join returns the narrowest type both a and b are subtypes of, e.g. the
+// type of a variable that is assigned both.
+func Join(a *Type, b *Type) *Type {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.Kind == TK_Any || b.Kind == TK_Any {
+		return Any
+	}
+	if a.joinsTo(b) {
+		return b
+	}
+	if b.joinsTo(a) {
+		return a
+	}
+	if a.Kind == TK_Array && b.Kind == TK_Array {
+		return ArrayOf(Join(a.Elem(), b.Elem()))
+	}
+	return UnionOf(a, b)
+}
+
+// UnionOf builds the union of the given types, flattening nested unions
+// and leaving out members that are subtypes of other members.
+func UnionOf(ts ...*Type) *Type {
+	flat := make([]*Type, 0, len(ts))
+	for _, t := range ts {
+		if t.Kind == TK_Union {
+			flat = append(flat, t.Members...)
+		} else {
+			flat = append(flat, t)
+		}
+	}
+
+	// Of two members that are subtypes of each other, the first is kept.
+	members := make([]*Type, 0, len(flat))
+	for i, t := range flat {
+		if t.Kind == TK_Any {
+			return Any
+		}
+		subsumed := false
+		for j, o := range flat {
+			if i != j && t.joinsTo(o) && (j < i || !o.joinsTo(t)) {
+				subsumed = true
+				break
+			}
+		}
+		if !subsumed {
+			members = append(members, t)
+		}
+	}
+
+	if len(members) == 1 {
+		return members[0]
+	}
+	return &Type{Kind: TK_Union, Members: members}
+}
+
+// Optional returns t | Undefined.
+func Optional(t *Type) *Type {
+	return UnionOf(t, Undefined)
+}
+
+type TypeParseError struct{ error }
+
+// ParseType reads a type written the way Feather writes them, e.g.
+// "Real", "Array<String>", "Id.DsMap<Real>", "Struct.Vector2",
+// "Asset.GMSprite" or "Real|Undefined". Names are case-insensitive,
+// the older "Array[Real]" form is accepted, and unions may also be
+// separated by commas.
+func ParseType(s string) (*Type, error) {
+	tp := typeParser{src: s}
+	t, err := tp.union(true)
+	if err == nil && tp.peek() != 0 {
+		err = fmt.Errorf("unexpected %q", tp.src[tp.pos:])
+	}
+	if err != nil {
+		return nil, TypeParseError{fmt.Errorf("type %q: %w", s, err)}
+	}
+	return t, nil
+}
+
+type typeParser struct {
+	src string
+	pos int
+}
+
+func (tp *typeParser) skip() {
+	for tp.pos < len(tp.src) && (tp.src[tp.pos] == ' ' || tp.src[tp.pos] == '\t') {
+		tp.pos++
+	}
+}
+
+func (tp *typeParser) peek() byte {
+	tp.skip()
+	if tp.pos >= len(tp.src) {
+		return 0
+	}
+	return tp.src[tp.pos]
+}
+
+func (tp *typeParser) ident() string {
+	tp.skip()
+	start := tp.pos
+	for tp.pos < len(tp.src) {
+		c := tp.src[tp.pos]
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(tp.pos > start && c >= '0' && c <= '9') {
+			break
+		}
+		tp.pos++
+	}
+	return tp.src[start:tp.pos]
+}
+
+// union parses alternatives separated by '|', or also by ',' at the top
+// level, where a comma can't mean anything else.
+func (tp *typeParser) union(commas bool) (*Type, error) {
+	members := make([]*Type, 0, 1)
+	for {
+		t, err := tp.member()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, t)
+
+		c := tp.peek()
+		if c != '|' && !(commas && c == ',') {
+			break
+		}
+		tp.pos++
+	}
+	return UnionOf(members...), nil
+}
+
+// params parses type arguments, either <A, B> or the older [A].
+func (tp *typeParser) params() ([]*Type, error) {
+	var closing byte
+	switch tp.peek() {
+	case '<':
+		closing = '>'
+	case '[':
+		closing = ']'
+	default:
+		return nil, nil
+	}
+	tp.pos++
+
+	out := make([]*Type, 0, 1)
+	for {
+		t, err := tp.union(false)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+
+		switch tp.peek() {
+		case ',':
+			tp.pos++
+			continue
+		case closing:
+			tp.pos++
+			return out, nil
+		}
+		return nil, fmt.Errorf("expected ',' or %q", closing)
+	}
+}
+
+func (tp *typeParser) member() (*Type, error) {
+	name := tp.ident()
+	if name == "" {
+		if tp.pos >= len(tp.src) {
+			return nil, fmt.Errorf("missing type")
+		}
+		return nil, fmt.Errorf("unexpected %q", tp.src[tp.pos])
+	}
+
+	sub := ""
+	if tp.peek() == '.' {
+		tp.pos++
+		sub = tp.ident()
+		if sub == "" {
+			return nil, fmt.Errorf("missing name after %v.", name)
+		}
+	}
+
+	params, err := tp.params()
+	if err != nil {
+		return nil, err
+	}
+
+	simple := func(t *Type) (*Type, error) {
+		if sub != "" || params != nil {
+			return nil, fmt.Errorf("%v takes no name or parameters", name)
+		}
+		return t, nil
+	}
+
+	switch strings.ToLower(name) {
+	case "any":
+		return simple(Any)
+	case "mixed":
+		return simple(Mixed)
+	case "undefined", "void":
+		return simple(Undefined)
+	case "real", "number":
+		return simple(Real)
+	case "int64":
+		return simple(Int64)
+	case "bool", "boolean":
+		return simple(Bool)
+	case "string":
+		return simple(String)
+
+	case "constant":
+		if sub == "" || params != nil {
+			return nil, fmt.Errorf("Constant needs a name, like Constant.Colour")
+		}
+		return &Type{Kind: TK_Real, Name: sub}, nil
+
+	case "pointer":
+		if params != nil {
+			return nil, fmt.Errorf("Pointer takes no parameters")
+		}
+		return &Type{Kind: TK_Pointer, Name: sub}, nil
+
+	case "array":
+		if sub != "" || len(params) > 1 {
+			return nil, fmt.Errorf("Array takes a single element type")
+		}
+		if len(params) == 0 {
+			return ArrayOf(Any), nil
+		}
+		return ArrayOf(params[0]), nil
+
+	case "struct":
+		if params != nil {
+			return nil, fmt.Errorf("Struct takes no parameters")
+		}
+		return StructOf(sub), nil
+
+	case "function", "method":
+		if params != nil {
+			return nil, fmt.Errorf("Function takes no parameters")
+		}
+		return &Type{Kind: TK_Function, Name: sub}, nil
+
+	case "enum":
+		if sub == "" || params != nil {
+			return nil, fmt.Errorf("Enum needs a name, like Enum.Colours")
+		}
+		return EnumOf(sub), nil
+
+	case "asset":
+		if params != nil {
+			return nil, fmt.Errorf("Asset takes no parameters")
+		}
+		return AssetOf(sub), nil
+
+	case "id":
+		return IdOf(sub, params...), nil
+	}
+
+	return nil, fmt.Errorf("unknown type %v", name)
+}
@@ -0,0 +1,256 @@
+package typecheck
+
+import (
+	"errors"
+	"testing"
+)
+
+func mustParseType(t *testing.T, s string) *Type {
+	t.Helper()
+	ty, err := ParseType(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ty
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"Real", "Real"},
+		{"number", "Real"},
+		{"BOOLEAN", "Bool"},
+		{"void", "Undefined"},
+		{"Array", "Array"},
+		{"Array<String>", "Array<String>"},
+		{"Array[Real]", "Array<Real>"},
+		{"Array<Array<Real>>", "Array<Array<Real>>"},
+		{"Struct", "Struct"},
+		{"Struct.Vector2", "Struct.Vector2"},
+		{"Function.scr_move", "Function.scr_move"},
+		{"Enum.Colours", "Enum.Colours"},
+		{"Asset.GMSprite", "Asset.GMSprite"},
+		{"Id.DsMap<Real>", "Id.DsMap<Real>"},
+		{"Id.Instance", "Id.Instance"},
+		{"Pointer.Texture", "Pointer.Texture"},
+		{"Constant.Colour", "Constant.Colour"},
+		{"Real|Undefined", "Real | Undefined"},
+		{"Real, String", "Real | String"},
+		{" Real | Real ", "Real"},
+		{"Array<Real|String>", "Array<Real | String>"},
+		{"Real|Any", "Any"},
+	}
+	for _, test := range tests {
+		ty, err := ParseType(test.src)
+		if err != nil {
+			t.Errorf("%q: %v", test.src, err)
+			continue
+		}
+		if got := ty.String(); got != test.want {
+			t.Errorf("%q parsed as %v, want %v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestParseTypeErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"Real|",
+		"Realm",
+		"Real String",
+		"Array<Real, String>",
+		"Array<Real",
+		"Real.Thing",
+		"Constant",
+		"Enum",
+		"Struct<Real>",
+		"Id.",
+		"3D",
+	}
+	for _, src := range tests {
+		_, err := ParseType(src)
+		if err == nil {
+			t.Errorf("%q parsed", src)
+		} else if !errors.As(err, &TypeParseError{}) {
+			t.Errorf("%q: got %T, want a TypeParseError", src, err)
+		}
+	}
+}
+
+func TestSubtypeOf(t *testing.T) {
+	tests := []struct {
+		t, u string
+		want bool
+	}{
+		{"Real", "Real", true},
+		{"Real", "Any", true},
+		{"Any", "String", true},
+		{"String", "Mixed", true},
+		{"Mixed", "String", false},
+		{"Real", "String", false},
+		{"Undefined", "Real", false},
+		{"Int64", "Real", true},
+		{"Real", "Bool", true},
+		{"Enum.Colours", "Bool", true},
+		{"Bool", "Real", true},
+		{"Enum.Colours", "Real", true},
+		{"Real", "Enum.Colours", false},
+		{"Enum.Colours", "Enum.Sizes", false},
+		{"Constant.Colour", "Real", true},
+		{"Real", "Real|Undefined", true},
+		{"Real|Undefined", "Real", false},
+		{"Real|String", "String|Real|Undefined", true},
+		{"Array<Real>", "Array", true},
+		{"Array", "Array<Real>", true},
+		{"Array<Real>", "Array<String>", false},
+		{"Asset.GMSprite", "Asset", true},
+		{"Asset.GMSprite", "Asset.GMSound", false},
+		{"Id.DsMap<Real>", "Id.DsMap", true},
+		{"Id.DsMap<Real>", "Id.DsMap<String>", false},
+		{"Id.DsMap", "Id.DsList", false},
+		{"Struct.Vector2", "Struct", true},
+		{"Struct.Vector2", "Struct.Vector3", false},
+		{"Function.scr_a", "Function", true},
+		{"Function.scr_a", "Function.scr_b", false},
+	}
+	for _, test := range tests {
+		a, b := mustParseType(t, test.t), mustParseType(t, test.u)
+		if got := a.SubtypeOf(b); got != test.want {
+			t.Errorf("%v subtype of %v: got %v, want %v", test.t, test.u, got, test.want)
+		}
+	}
+}
+
+func TestSubtypeIn(t *testing.T) {
+	descends := func(object, ancestor string) bool {
+		return object == "obj_child" && ancestor == "obj_parent"
+	}
+	tests := []struct {
+		t, u *Type
+		want bool
+	}{
+		{InstanceOf("obj_child"), InstanceOf("obj_parent"), true},
+		{InstanceOf("obj_parent"), InstanceOf("obj_child"), false},
+		{InstanceOf("obj_other"), InstanceOf("obj_parent"), false},
+		{InstanceOf("obj_child"), IdOf("Instance"), true},
+		{InstanceOf(""), InstanceOf("obj_parent"), true},
+		{ArrayOf(InstanceOf("obj_child")), ArrayOf(InstanceOf("obj_parent")), true},
+		{Optional(InstanceOf("obj_child")), Optional(InstanceOf("obj_parent")), true},
+		{InstanceOf("obj_child"), IdOf("DsMap"), false},
+	}
+	for _, test := range tests {
+		if got := test.t.SubtypeIn(test.u, descends); got != test.want {
+			t.Errorf("%v subtype of %v: got %v, want %v", test.t, test.u, got, test.want)
+		}
+	}
+
+	// Without descends, instances are only instances of their own object.
+	if InstanceOf("obj_child").SubtypeOf(InstanceOf("obj_parent")) {
+		t.Errorf("obj_child is an obj_parent without its parents")
+	}
+}
+
+func TestSubtypeFields(t *testing.T) {
+	vec := StructOf("", Field{"x", Real}, Field{"y", Real})
+	tests := []struct {
+		t, u *Type
+		want bool
+	}{
+		{vec, StructOf("", Field{"x", Real}), true},
+		{StructOf("", Field{"x", Real}), vec, false},
+		{StructOf("", Field{"x", String}), StructOf("", Field{"x", Real}), false},
+		{StructOf(""), vec, true},
+		{StructOf("Vec", Field{"x", Real}), StructOf("Vec"), true},
+	}
+	for _, test := range tests {
+		if got := test.t.SubtypeOf(test.u); got != test.want {
+			t.Errorf("%v subtype of %v: got %v, want %v", test.t, test.u, got, test.want)
+		}
+	}
+}
+
+func TestSubtypeFunctions(t *testing.T) {
+	fn := func(ret *Type, params ...Param) *Type {
+		return FunctionOf(&Signature{Params: params, Return: ret})
+	}
+	real_to_real := fn(Real, Param{Name: "_x", Type: Real})
+	tests := []struct {
+		t, u *Type
+		want bool
+	}{
+		{real_to_real, real_to_real, true},
+		// Taking more optional arguments is fine, needing more isn't.
+		{fn(Real, Param{Type: Real}, Param{Type: Real, Optional: true}), real_to_real, true},
+		{fn(Real, Param{Type: Real}, Param{Type: Real}), real_to_real, false},
+		// Parameters are compared the other way around from returns.
+		{fn(Real, Param{Type: Optional(Real)}), real_to_real, true},
+		{real_to_real, fn(Real, Param{Type: Optional(Real)}), false},
+		{fn(Int64, Param{Type: Real}), real_to_real, true},
+		{fn(String, Param{Type: Real}), real_to_real, false},
+		{FunctionOf(&Signature{Rest: Any}), real_to_real, true},
+		{real_to_real, FunctionOf(&Signature{Rest: Any}), false},
+	}
+	for _, test := range tests {
+		if got := test.t.SubtypeOf(test.u); got != test.want {
+			t.Errorf("%v subtype of %v: got %v, want %v", test.t, test.u, got, test.want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"Real", "Real", "Real"},
+		{"Int64", "Real", "Real"},
+		{"Real", "String", "Real | String"},
+		{"Real", "Undefined", "Real | Undefined"},
+		{"Real|Undefined", "Real", "Real | Undefined"},
+		{"Real|Undefined", "String", "Real | Undefined | String"},
+		{"Real", "Any", "Any"},
+		{"Mixed", "Real", "Mixed"},
+		// Numbers can be used as booleans, but don't become booleans.
+		{"Real", "Bool", "Real"},
+		{"Bool", "Real", "Real"},
+		{"Bool", "String", "Bool | String"},
+		{"Bool", "Bool", "Bool"},
+		{"Enum.Colours", "Real", "Real"},
+		{"Array<Real>", "Array<String>", "Array<Real | String>"},
+		{"Array<Int64>", "Array<Real>", "Array<Real>"},
+		{"Array<Real>", "String", "Array<Real> | String"},
+	}
+	for _, test := range tests {
+		a, b := mustParseType(t, test.a), mustParseType(t, test.b)
+		if got := Join(a, b).String(); got != test.want {
+			t.Errorf("Join(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+
+	if Join(nil, Real) != Real || Join(Real, nil) != Real {
+		t.Errorf("joining with nil doesn't give the other type")
+	}
+}
+
+func TestSignatureArgs(t *testing.T) {
+	tests := []struct {
+		sig      *Signature
+		want     string
+		min, max int
+	}{
+		{&Signature{Params: []Param{{Name: "_a", Type: Real}, {Name: "_b", Type: Real, Optional: true}}}, "(_a: Real, _b?: Real)", 1, 2},
+		{&Signature{Params: []Param{{Type: Real, Optional: true}, {Type: Real}}}, "(?Real, Real)", 2, 2},
+		{&Signature{}, "()", 0, 0},
+		{&Signature{Rest: Any}, "(...Any)", 0, -1},
+	}
+	for _, test := range tests {
+		if got := FunctionOf(test.sig).String(); got != "Function"+test.want {
+			t.Errorf("got %v, want Function%v", got, test.want)
+		}
+		if got_min, got_max := test.sig.MinArgs(), test.sig.MaxArgs(); got_min != test.min || got_max != test.max {
+			t.Errorf("%v takes %v to %v arguments, want %v to %v", test.want, got_min, got_max, test.min, test.max)
+		}
+	}
+}
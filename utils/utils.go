@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"errors"
 	"strings"
+	"sync"
 )
 
 func IsBetween(char byte, start byte, end byte) bool {
@@ -102,3 +103,57 @@ func ErrorCount[K any](es Errors) int {
 	}
 	return count
 }
+
+// ParallelFor calls fn for every index in [0, count) using at most jobs
+// goroutines. It returns once all calls have finished.
+func ParallelFor(count int, jobs int, fn func(i int)) {
+	if jobs <= 1 || count <= 1 {
+		for i := 0; i < count; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < min(jobs, count); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// EditDistance is the number of single byte insertions, deletions and
+// substitutions needed to turn a into b.
+func EditDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}